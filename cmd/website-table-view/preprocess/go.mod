@@ -1,3 +1,8 @@
 module dkfbasel.ch/preprocess
 
 go 1.14
+
+require dkfbasel.ch/covid-evidence v0.0.0
+
+// the exclusion rules are shared with the pipeline
+replace dkfbasel.ch/covid-evidence => ../../../pipeline
@@ -6,6 +6,8 @@ import (
 	"log"
 	"os"
 	"strings"
+
+	"dkfbasel.ch/covid-evidence/rules"
 )
 
 type coveBasic struct {
@@ -18,6 +20,7 @@ type coveBasic struct {
 	InterventionName    string `json:"intervention_name"`
 	NumberEnrollment    int    `json:"n_enrollment"`
 	Country             string `json:"country"`
+	Countries           string `json:"countries"`
 	Status              string `json:"status"`
 	Randomized          string `json:"randomized"`
 	NumberArms          int    `json:"n_arms"`
@@ -55,43 +58,32 @@ func main() {
 		log.Fatalf("could not parse data: %+v", err)
 	}
 
+	// decode the records a second time as generic records to evaluate the
+	// exclusion rules on all fields
+	var records []map[string]interface{}
+	err = json.Unmarshal(content, &records)
+	if err != nil {
+		log.Fatalf("could not parse data: %+v", err)
+	}
+
 	var filtered []*coveBasic
 
-	covidFiltered := 0
-	trialFiltered := 0
-	duplicateFiltered := 0
-	sourceFiltered := 0
-	idFiltered := 0
+	// count the records filtered by each rule
+	filteredBy := make(map[string]int)
 
-	// filter out all data that is not a trial
-	for i, item := range dta {
+	// filter out all data that is excluded from covebasic (see the rules of
+	// the pipeline) or can not be published on the website
+	for i := range dta {
 
-		// skip all non covid items
-		if item.IsCovid == "no" {
-			covidFiltered++
-			continue
-		}
+		fields := rules.Fields(records[i])
 
-		// skip all non trial items
-		if item.IsTrial == "no" {
-			trialFiltered++
-			continue
-		}
-
-		// skip all duplicates
-		if item.IsDuplicate {
-			duplicateFiltered++
-			continue
+		rule, ok := rules.Excluded(fields)
+		if !ok {
+			rule, ok = rules.Publication.Match(fields)
 		}
 
-		if item.Source == "" {
-			sourceFiltered++
-			continue
-		}
-
-		// skip all items without covid id
-		if item.CoveID == 0 {
-			idFiltered++
+		if ok {
+			filteredBy[rule.Name]++
 			continue
 		}
 
@@ -109,11 +101,34 @@ func main() {
 		log.Fatalf("could not generate output file: %+v", err)
 	}
 
+	// count the number of trials per participating country
+	countryCounts := make(map[string]int)
+	for _, item := range filtered {
+		for _, country := range strings.Split(item.Countries, ";") {
+			country = strings.TrimSpace(country)
+			if country == "" {
+				continue
+			}
+			countryCounts[country]++
+		}
+	}
+
+	output, err = json.Marshal(countryCounts)
+	if err != nil {
+		log.Fatalf("could not generate country output: %+v", err)
+	}
+
+	countriesName := strings.Replace(fileName, ".json", "_countries.json", 1)
+	err = ioutil.WriteFile(countriesName, output, 0644)
+	if err != nil {
+		log.Fatalf("could not generate country output file: %+v", err)
+	}
+
 	log.Printf("Input: %d, Filtered: %d", len(dta), len(filtered))
-	log.Printf("Covid:   %d", covidFiltered)
-	log.Printf("Trial:   %d", trialFiltered)
-	log.Printf("Dupl.:   %d", duplicateFiltered)
-	log.Printf("Source:  %d", sourceFiltered)
-	log.Printf("ID:      %d", idFiltered)
+	for _, set := range []rules.Set{rules.Exclusion, rules.Publication} {
+		for _, rule := range set {
+			log.Printf("%-12s %d", rule.Name+":", filteredBy[rule.Name])
+		}
+	}
 
 }
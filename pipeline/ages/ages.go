@@ -0,0 +1,165 @@
+package ages
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// age categories used in covebasic (according to the clinicaltrials.gov
+// definitions: children 0-17, adults 18-64, older adults 65+)
+const (
+	Children    = "children"
+	Adults      = "adults"
+	OlderAdults = "older adults"
+)
+
+// categories defines the age bounds in years of each category
+var categories = []struct {
+	Name string
+	Min  float64
+	Max  float64
+}{
+	{Children, 0, 18},
+	{Adults, 18, 65},
+	{OlderAdults, 65, math.Inf(1)},
+}
+
+// units contains the number of years of each unit. the units are checked in
+// order, minutes must therefore be listed before the months abbreviated with m
+var units = []struct {
+	Prefixes []string
+	Years    float64
+}{
+	{[]string{"year", "yr", "y", "jahr", "j"}, 1},
+	{[]string{"minute", "min"}, 1.0 / (365 * 24 * 60)},
+	{[]string{"month", "mo", "m", "monat"}, 1.0 / 12},
+	{[]string{"week", "wk", "w", "woche"}, 7.0 / 365},
+	{[]string{"day", "d", "tag"}, 1.0 / 365},
+	{[]string{"hour", "h", "stunde"}, 1.0 / (365 * 24)},
+}
+
+// comparisons contains the prefixes of ages given as comparison (i.e. ">18
+// years", "under 65") and whether the age itself is excluded. the prefixes are
+// checked in order, ">=" must therefore be listed before ">"
+var comparisons = []struct {
+	Prefixes  []string
+	Exclusive bool
+}{
+	{[]string{">=", "=>", "≥", "<=", "=<", "≤", "=", "at least", "up to"}, false},
+	{[]string{">", "<", "over", "above", "older than", "more than", "under", "below", "younger than", "less than"}, true},
+}
+
+// Parse will convert the given age (i.e. "18 Years", "6 Months", "18Y",
+// ">18 years") into years. values without bounds (i.e. "N/A", "No limit")
+// return false
+func Parse(value string) (float64, bool) {
+	age, _, ok := parseBound(value)
+	return age, ok
+}
+
+// Years will convert the given age into years for the numeric age fields,
+// rounded to two decimals. the value is empty if the age has no bound
+func Years(value string) (interface{}, bool) {
+
+	age, ok := Parse(value)
+	if !ok {
+		return "", true
+	}
+
+	return math.Round(age*100) / 100, true
+}
+
+// parseBound will convert the given age into years and check if the age itself
+// is excluded by a comparison (i.e. "<18 years")
+func parseBound(value string) (float64, bool, bool) {
+
+	value, exclusive := trimComparison(strings.ToLower(strings.TrimSpace(value)))
+
+	// split the number from the unit
+	i := strings.IndexFunc(value, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.' && r != ','
+	})
+
+	number := value
+	unit := ""
+	if i != -1 {
+		number = value[:i]
+		unit = strings.TrimSpace(value[i:])
+	}
+
+	number = strings.Replace(number, ",", ".", 1)
+
+	age, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, false, false
+	}
+
+	// values without unit are considered to be years
+	if unit == "" {
+		return age, exclusive, true
+	}
+
+	for _, u := range units {
+		for _, prefix := range u.Prefixes {
+			if strings.HasPrefix(unit, prefix) {
+				return age * u.Years, exclusive, true
+			}
+		}
+	}
+
+	return 0, false, false
+}
+
+// trimComparison will remove the comparison from the given age and return
+// whether the age itself is excluded
+func trimComparison(value string) (string, bool) {
+	for _, c := range comparisons {
+		for _, prefix := range c.Prefixes {
+			if strings.HasPrefix(value, prefix) {
+				return strings.TrimSpace(value[len(prefix):]), c.Exclusive
+			}
+		}
+	}
+	return value, false
+}
+
+// Categories will return all age categories covered by the given minimum and
+// maximum age separated by semicolon. a missing bound is considered to be open,
+// no categories are returned if both bounds are missing
+func Categories(minimum string, maximum string) string {
+
+	min, hasMin := Parse(minimum)
+	if !hasMin {
+		min = 0
+	}
+
+	max, exclusiveMax, hasMax := parseBound(maximum)
+	if !hasMax {
+		max = math.Inf(1)
+	}
+
+	if !hasMin && !hasMax {
+		return ""
+	}
+
+	// the maximum age is inclusive (i.e. 17 years includes 17.9 years), unless
+	// it is excluded by the comparison (i.e. "<18 years")
+	if !math.IsInf(max, 1) && !exclusiveMax {
+		max = math.Floor(max) + 1
+	}
+
+	if max <= min {
+		return ""
+	}
+
+	names := []string{}
+	for _, c := range categories {
+		if min < c.Max && max > c.Min {
+			names = append(names, c.Name)
+		}
+	}
+
+	return strings.Join(names, "; ")
+}
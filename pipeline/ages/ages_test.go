@@ -0,0 +1,101 @@
+package ages
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+
+	tests := []struct {
+		value string
+		years float64
+		ok    bool
+	}{
+		{"18 Years", 18, true},
+		{"18Y", 18, true},
+		{"65", 65, true},
+		{"6 Months", 0.5, true},
+		{"6 m", 0.5, true},
+		{"2 Weeks", 14.0 / 365, true},
+		{"28 Days", 28.0 / 365, true},
+		{"24 Hours", 1.0 / 365, true},
+		{"30 Minutes", 30.0 / (365 * 24 * 60), true},
+		{"30 min", 30.0 / (365 * 24 * 60), true},
+		{"16,5 Jahre", 16.5, true},
+		{">18 years", 18, true},
+		{">= 18 Years", 18, true},
+		{"≥18 years", 18, true},
+		{"<65", 65, true},
+		{"≤ 6 Months", 0.5, true},
+		{"over 60 years", 60, true},
+		{"Under 12 years", 12, true},
+		{"at least 18 years", 18, true},
+		{"> N/A", 0, false},
+		{"N/A", 0, false},
+		{"No limit", 0, false},
+		{"", 0, false},
+	}
+
+	for _, test := range tests {
+		years, ok := Parse(test.value)
+		if ok != test.ok || math.Abs(years-test.years) > 1e-9 {
+			t.Errorf("Parse(%q) = %v, %v, expected %v, %v", test.value, years, ok, test.years, test.ok)
+		}
+	}
+}
+
+func TestCategories(t *testing.T) {
+
+	tests := []struct {
+		minimum string
+		maximum string
+		want    string
+	}{
+		{"18 Years", "", "adults; older adults"},
+		{"18 Years", "64 Years", "adults"},
+		{"18 Years", "65 Years", "adults; older adults"},
+		{"", "17 Years", "children"},
+		{"N/A", "17 Years", "children"},
+		{"12 Years", "80 Years", "children; adults; older adults"},
+		{"65 Years", "N/A", "older adults"},
+		{"30 Minutes", "12 Hours", "children"},
+		{"70 Years", "18 Years", ""},
+		{"", "", ""},
+		{"N/A", "N/A", ""},
+		{">18 years", "", "adults; older adults"},
+		{">=18 years", "<65 years", "adults"},
+		{"", "<18 years", "children"},
+		{"", "under 18 years", "children"},
+		{"", "<=18 years", "children; adults"},
+		{"over 65 years", "", "older adults"},
+		{"≥ 12 years", "≤ 64 years", "children; adults"},
+	}
+
+	for _, test := range tests {
+		if got := Categories(test.minimum, test.maximum); got != test.want {
+			t.Errorf("Categories(%q, %q) = %q, expected %q", test.minimum, test.maximum, got, test.want)
+		}
+	}
+}
+
+func TestYears(t *testing.T) {
+
+	tests := []struct {
+		value string
+		years interface{}
+	}{
+		{"18 Years", 18.0},
+		{">18 years", 18.0},
+		{"6 Months", 0.5},
+		{"28 Days", 0.08},
+		{"N/A", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if years, _ := Years(test.value); years != test.years {
+			t.Errorf("Years(%q) = %v, expected %v", test.value, years, test.years)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package arms
+
+import (
+	"strings"
+
+	"dkfbasel.ch/covid-evidence/helpers"
+)
+
+// control types derived from the arms of a trial
+const (
+	ControlPlacebo        = "placebo"
+	ControlStandardOfCare = "standard of care"
+	ControlActive         = "active"
+	ControlNone           = "none"
+)
+
+// Arm contains the information on a single arm of a trial
+type Arm struct {
+	Label         string   `json:"label"`
+	Type          string   `json:"type"`
+	Description   string   `json:"description"`
+	Interventions []string `json:"interventions"`
+}
+
+// standardOfCareTerms are used to identify arms that receive standard of care
+var standardOfCareTerms = []string{
+	"standard of care", "standard care", "standard treatment",
+	"standard therapy", "usual care", "best supportive care",
+	"supportive care", "conventional", "routine care", "soc",
+}
+
+// IsControl will check if the arm is a control arm
+func (a Arm) IsControl() bool {
+	armType := strings.ToLower(a.Type)
+	return strings.Contains(armType, "comparator") ||
+		strings.Contains(armType, "no intervention") ||
+		strings.Contains(armType, "control")
+}
+
+// ControlType will return the type of control of the arm or an empty string
+// if the arm is not a control arm
+func (a Arm) ControlType() string {
+
+	if !a.IsControl() {
+		return ""
+	}
+
+	armType := strings.ToLower(a.Type)
+	text := strings.ToLower(a.Label + " " + a.Description + " " +
+		strings.Join(a.Interventions, " "))
+
+	if strings.Contains(armType, "placebo") || strings.Contains(armType, "sham") ||
+		strings.Contains(text, "placebo") {
+		return ControlPlacebo
+	}
+
+	if strings.Contains(armType, "no intervention") {
+		return ControlStandardOfCare
+	}
+
+	for _, term := range standardOfCareTerms {
+		if helpers.ContainsWord(text, term) {
+			return ControlStandardOfCare
+		}
+	}
+
+	return ControlActive
+}
+
+// ControlType will return the types of control used in the given arms,
+// separated by semicolon. single arm trials have no control
+func ControlType(arms []Arm) string {
+
+	if len(arms) == 0 {
+		return ""
+	}
+
+	if len(arms) == 1 {
+		return ControlNone
+	}
+
+	found := make(map[string]bool)
+	for _, arm := range arms {
+		controlType := arm.ControlType()
+		if controlType != "" {
+			found[controlType] = true
+		}
+	}
+
+	// keep the order of the control types stable
+	types := []string{}
+	for _, controlType := range []string{ControlPlacebo, ControlStandardOfCare, ControlActive} {
+		if found[controlType] {
+			types = append(types, controlType)
+		}
+	}
+
+	return strings.Join(types, "; ")
+}
+
+// FromIctrp will extract the arms from the intervention field of ictrp,
+// which lists the groups as "label:description;" (i.e. "control group:
+// conventional treatment;experimental group:drug;"). interventions that are
+// not listed by group will not return any arms
+func FromIctrp(intervention string) []Arm {
+
+	arms := []Arm{}
+
+	for _, item := range strings.Split(intervention, ";") {
+
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		label := strings.TrimSpace(parts[0])
+		description := strings.TrimSpace(parts[1])
+
+		lowerLabel := strings.ToLower(label)
+		if !strings.Contains(lowerLabel, "group") && !strings.Contains(lowerLabel, "arm") {
+			continue
+		}
+
+		arm := Arm{
+			Label:       label,
+			Description: description,
+			Type:        "Experimental",
+		}
+
+		text := strings.ToLower(label + " " + description)
+		if strings.Contains(text, "placebo") {
+			arm.Type = "Placebo Comparator"
+		} else if strings.Contains(lowerLabel, "control") {
+			arm.Type = "Control"
+		}
+
+		arms = append(arms, arm)
+	}
+
+	return arms
+}
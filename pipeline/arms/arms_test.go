@@ -0,0 +1,42 @@
+package arms
+
+import "testing"
+
+func TestControlType(t *testing.T) {
+
+	experimental := Arm{Label: "Hydroxychloroquine", Type: "Experimental", Interventions: []string{"Drug: Hydroxychloroquine"}}
+
+	tests := []struct {
+		name string
+		arms []Arm
+		want string
+	}{
+		{"no arms", nil, ""},
+		{"single arm", []Arm{experimental}, ControlNone},
+		{"placebo comparator", []Arm{experimental,
+			{Label: "Control", Type: "Placebo Comparator"}}, ControlPlacebo},
+		{"sham comparator", []Arm{experimental,
+			{Label: "Sham", Type: "Sham Comparator"}}, ControlPlacebo},
+		{"placebo in interventions", []Arm{experimental,
+			{Label: "Control", Type: "Active Comparator", Interventions: []string{"Drug: Placebo"}}}, ControlPlacebo},
+		{"no intervention", []Arm{experimental,
+			{Label: "Control", Type: "No Intervention"}}, ControlStandardOfCare},
+		{"standard of care", []Arm{experimental,
+			{Label: "Standard of care", Type: "Active Comparator", Description: "SOC according to local guidelines"}}, ControlStandardOfCare},
+		{"active comparator", []Arm{experimental,
+			{Label: "Lopinavir/ritonavir", Type: "Active Comparator", Interventions: []string{"Drug: Lopinavir/ritonavir"}}}, ControlActive},
+		{"multiple controls", []Arm{experimental,
+			{Label: "Azithromycin", Type: "Active Comparator"},
+			{Label: "Placebo", Type: "Placebo Comparator"}}, "placebo; active"},
+		{"no control arm", []Arm{experimental,
+			{Label: "Chloroquine", Type: "Experimental"}}, ""},
+		{"ictrp groups", FromIctrp("control group:conventional treatment;experimental group:favipiravir;"), ControlStandardOfCare},
+		{"ictrp placebo group", FromIctrp("experimental group:drug;control group:placebo;"), ControlPlacebo},
+	}
+
+	for _, test := range tests {
+		if got := ControlType(test.arms); got != test.want {
+			t.Errorf("%s: ControlType = %q, expected %q", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"dkfbasel.ch/covid-evidence/manifest"
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+// Dir is the default directory of the backups
+const Dir = "./backups"
+
+// tablesFile contains the list of tables in the backup
+const tablesFile = "tables.json"
+
+// manifestFile contains the checksums of all files in the backup
+const manifestFile = "backup.manifest.json"
+
+// Table contains the information on a single table in the backup
+type Table struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	File    string `json:"file"`
+	Records int    `json:"records"`
+}
+
+// Backup is a snapshot of all tables in a directory, every table is stored as
+// json array of the records as returned by ninox (with ids and metadata)
+type Backup struct {
+	Dir    string
+	Tables []Table
+
+	manifest *manifest.Manifest
+}
+
+// New will initialize a new backup in the given directory
+func New(dir string) (*Backup, error) {
+
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("backup exists already: %s", dir)
+	}
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("could not create backup directory: %w", err)
+	}
+
+	return &Backup{
+		Dir:      dir,
+		manifest: manifest.New("backup", "ninox"),
+	}, nil
+}
+
+// Add will write the records of the given table into the backup
+func (b *Backup) Add(table ninox.Table, records []ninox.Record) error {
+
+	entry := Table{
+		Name:    table.Name,
+		URL:     table.URL,
+		File:    fileName(table.Name),
+		Records: len(records),
+	}
+
+	content, err := json.MarshalIndent(records, "", "\t")
+	if err != nil {
+		return fmt.Errorf("could not encode records of %s: %w", table.Name, err)
+	}
+
+	path := filepath.Join(b.Dir, entry.File)
+	err = ioutil.WriteFile(path, content, 0644)
+	if err != nil {
+		return fmt.Errorf("could not write records of %s: %w", table.Name, err)
+	}
+
+	err = b.manifest.AddOutput(path)
+	if err != nil {
+		return err
+	}
+
+	b.manifest.Counts[table.Name] = len(records)
+	b.Tables = append(b.Tables, entry)
+
+	return nil
+}
+
+// Close will write the list of tables and the manifest of the backup
+func (b *Backup) Close() error {
+
+	content, err := json.MarshalIndent(b.Tables, "", "\t")
+	if err != nil {
+		return fmt.Errorf("could not encode tables: %w", err)
+	}
+
+	path := filepath.Join(b.Dir, tablesFile)
+	err = ioutil.WriteFile(path, content, 0644)
+	if err != nil {
+		return fmt.Errorf("could not write tables: %w", err)
+	}
+
+	err = b.manifest.AddOutput(path)
+	if err != nil {
+		return err
+	}
+
+	return b.manifest.Write(filepath.Join(b.Dir, manifestFile))
+}
+
+// Open will open the backup in the given directory and verify the checksums
+// of all files
+func Open(dir string) (*Backup, error) {
+
+	m, err := manifest.Load(filepath.Join(dir, manifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	// the paths in the manifest are relative to the working directory of the
+	// backup, the files are therefore verified in the given directory
+	for i := range m.Outputs {
+		m.Outputs[i].Path = filepath.Join(dir, filepath.Base(m.Outputs[i].Path))
+	}
+
+	err = m.Verify()
+	if err != nil {
+		return nil, fmt.Errorf("could not verify backup: %w", err)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, tablesFile))
+	if err != nil {
+		return nil, fmt.Errorf("could not read tables: %w", err)
+	}
+
+	b := &Backup{Dir: dir, manifest: m}
+	err = json.Unmarshal(content, &b.Tables)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse tables: %w", err)
+	}
+
+	return b, nil
+}
+
+// Created will return the time the backup was completed
+func (b *Backup) Created() string {
+	return b.manifest.Completed.Format("2006-01-02 15:04:05")
+}
+
+// Records will read the records of the given table from the backup
+func (b *Backup) Records(table Table) ([]ninox.Record, error) {
+
+	content, err := ioutil.ReadFile(filepath.Join(b.Dir, table.File))
+	if err != nil {
+		return nil, fmt.Errorf("could not read records of %s: %w", table.Name, err)
+	}
+
+	var records []ninox.Record
+	err = json.Unmarshal(content, &records)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse records of %s: %w", table.Name, err)
+	}
+
+	return records, nil
+}
+
+// nonAlphanumeric is used to create file names from the table names
+var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+
+// fileName will return the file name of the given table in the backup
+func fileName(table string) string {
+	name := nonAlphanumeric.ReplaceAllString(strings.ToLower(table), "-")
+	return strings.Trim(name, "-") + ".json"
+}
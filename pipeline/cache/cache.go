@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrOffline is returned if a request is not cached and the cache is offline
+var ErrOffline = errors.New("request not cached in offline mode")
+
+// Cache stores responses on disk. the content is stored by its hash in the
+// objects directory, the requests directory contains the metadata of each
+// request (indexed by the hash of the request) with a reference to the content
+type Cache struct {
+	Dir string
+
+	// responses older than the ttl are fetched again (unless offline)
+	TTL time.Duration
+
+	// offline will only replay cached responses regardless of their age
+	Offline bool
+}
+
+// Entry contains the metadata of a cached request
+type Entry struct {
+	Request   string    `json:"request"`
+	Content   string    `json:"content"` // sha256 hash of the content
+	Size      int       `json:"size"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// New will initialize a new cache in the given directory
+func New(dir string, ttl time.Duration) *Cache {
+	return &Cache{Dir: dir, TTL: ttl}
+}
+
+// Fetch will return the cached content of the given request or call the
+// fetch function and store its content in the cache
+func (c *Cache) Fetch(request string, fetch func() ([]byte, error)) ([]byte, error) {
+
+	content, ok, err := c.Get(request)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return content, nil
+	}
+
+	if c.Offline {
+		return nil, fmt.Errorf("%w: %s", ErrOffline, request)
+	}
+
+	content, err = fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.Put(request, content)
+	if err != nil {
+		return nil, err
+	}
+
+	return content, nil
+}
+
+// Get will return the cached content of the given request. the content is
+// only returned if it is not expired (or if the cache is offline)
+func (c *Cache) Get(request string) ([]byte, bool, error) {
+
+	entry, ok, err := c.Entry(request)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	if !c.Offline && c.TTL > 0 && time.Since(entry.FetchedAt) > c.TTL {
+		return nil, false, nil
+	}
+
+	// ignore entries with a corrupted reference to the content
+	if !validHash(entry.Content) {
+		return nil, false, nil
+	}
+
+	content, err := ioutil.ReadFile(c.objectPath(entry.Content))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("could not read cached content: %w", err)
+	}
+
+	// ignore content that was modified or only partially written
+	if hash(content) != entry.Content {
+		return nil, false, nil
+	}
+
+	return content, true, nil
+}
+
+// Entry will return the metadata of the given request
+func (c *Cache) Entry(request string) (Entry, bool, error) {
+
+	var entry Entry
+
+	content, err := ioutil.ReadFile(c.requestPath(request))
+	if os.IsNotExist(err) {
+		return entry, false, nil
+	}
+	if err != nil {
+		return entry, false, fmt.Errorf("could not read cache entry: %w", err)
+	}
+
+	err = json.Unmarshal(content, &entry)
+	if err != nil {
+		return entry, false, fmt.Errorf("could not parse cache entry: %w", err)
+	}
+
+	return entry, true, nil
+}
+
+// Put will store the content of the given request in the cache
+func (c *Cache) Put(request string, content []byte) error {
+
+	entry := Entry{
+		Request:   request,
+		Content:   hash(content),
+		Size:      len(content),
+		FetchedAt: time.Now(),
+	}
+
+	err := writeFile(c.objectPath(entry.Content), content)
+	if err != nil {
+		return fmt.Errorf("could not store content: %w", err)
+	}
+
+	metadata, err := json.MarshalIndent(entry, "", "\t")
+	if err != nil {
+		return fmt.Errorf("could not marshal cache entry: %w", err)
+	}
+
+	err = writeFile(c.requestPath(request), metadata)
+	if err != nil {
+		return fmt.Errorf("could not store cache entry: %w", err)
+	}
+
+	return nil
+}
+
+// requestPath will return the path of the metadata of the given request
+func (c *Cache) requestPath(request string) string {
+	return filepath.Join(c.Dir, "requests", hash([]byte(request))+".json")
+}
+
+// objectPath will return the path of the content with the given hash, the
+// hash must be valid (see validHash)
+func (c *Cache) objectPath(contentHash string) string {
+	return filepath.Join(c.Dir, "objects", contentHash[:2], contentHash)
+}
+
+// writeFile will write the content to a temporary file first and rename it
+// afterwards, to avoid partially written files if the process is interrupted
+func writeFile(path string, content []byte) error {
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	err = ioutil.WriteFile(tmp, content, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// hash will return the hex encoded sha256 hash of the content
+func hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// validHash will check if the given value is a hex encoded sha256 hash
+func validHash(value string) bool {
+	if len(value) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCache will create a cache in a temporary directory
+func testCache(t *testing.T) (*Cache, func()) {
+
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatalf("could not create temporary directory: %v", err)
+	}
+
+	return New(dir, time.Hour), func() { os.RemoveAll(dir) } // nolint:errcheck
+}
+
+func TestFetchOfflineReplay(t *testing.T) {
+
+	c, cleanup := testCache(t)
+	defer cleanup()
+
+	calls := 0
+	fetch := func() ([]byte, error) {
+		calls++
+		return []byte("page 1"), nil
+	}
+
+	// the first request is fetched and stored
+	content, err := c.Fetch("https://example.org/?page=1", fetch)
+	if err != nil || string(content) != "page 1" || calls != 1 {
+		t.Fatalf("Fetch returned %q, %v after %d calls", content, err, calls)
+	}
+
+	// expired responses are replayed offline without calling fetch
+	c.TTL = time.Nanosecond
+	c.Offline = true
+	time.Sleep(time.Millisecond)
+
+	content, err = c.Fetch("https://example.org/?page=1", fetch)
+	if err != nil || string(content) != "page 1" || calls != 1 {
+		t.Fatalf("offline Fetch returned %q, %v after %d calls", content, err, calls)
+	}
+
+	// requests that are not cached fail offline
+	_, err = c.Fetch("https://example.org/?page=2", fetch)
+	if !errors.Is(err, ErrOffline) || calls != 1 {
+		t.Fatalf("offline Fetch of missing request returned %v after %d calls", err, calls)
+	}
+
+	// expired responses are fetched again online
+	c.Offline = false
+	_, err = c.Fetch("https://example.org/?page=1", fetch)
+	if err != nil || calls != 2 {
+		t.Fatalf("Fetch of expired request returned %v after %d calls", err, calls)
+	}
+}
+
+func TestGetCorrupted(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		entry string
+	}{
+		{"short hash", `{"request": "r", "content": "a"}`},
+		{"empty hash", `{"request": "r", "content": ""}`},
+		{"invalid hash", `{"request": "r", "content": "` + strings.Repeat("z", 64) + `"}`},
+		{"missing content", `{"request": "r", "content": "` +
+			strings.Repeat("0", 64) + `"}`},
+	}
+
+	for _, test := range tests {
+
+		c, cleanup := testCache(t)
+
+		path := c.requestPath("r")
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = ioutil.WriteFile(path, []byte(test.entry), 0644)
+		}
+		if err != nil {
+			t.Fatalf("could not write entry: %v", err)
+		}
+
+		_, ok, err := c.Get("r")
+		if ok || err != nil {
+			t.Errorf("%s: Get returned %v, %v", test.name, ok, err)
+		}
+
+		cleanup()
+	}
+}
+
+func TestGetModified(t *testing.T) {
+
+	c, cleanup := testCache(t)
+	defer cleanup()
+
+	err := c.Put("r", []byte("content"))
+	if err != nil {
+		t.Fatalf("Put returned %v", err)
+	}
+
+	entry, _, _ := c.Entry("r")
+	err = ioutil.WriteFile(c.objectPath(entry.Content), []byte("partial"), 0644)
+	if err != nil {
+		t.Fatalf("could not modify content: %v", err)
+	}
+
+	_, ok, err := c.Get("r")
+	if ok || err != nil {
+		t.Errorf("Get of modified content returned %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,122 @@
+package countries
+
+import (
+	"strings"
+	"unicode"
+)
+
+// International is used as country for trials in multiple countries
+const International = "international"
+
+// index contains all country names, aliases and codes in normalized form
+var index map[string]string
+
+func init() {
+	index = make(map[string]string)
+	for _, c := range countries {
+		index[normalize(c.Code)] = c.Code
+		index[normalize(c.Name)] = c.Code
+		for _, alias := range c.Aliases {
+			index[normalize(alias)] = c.Code
+		}
+	}
+}
+
+// Code will return the ISO 3166 alpha-2 code for the given country name
+func Code(name string) (string, bool) {
+	code, ok := index[normalize(name)]
+	return code, ok
+}
+
+// Name will return the english short name for the given ISO 3166 code or the
+// code itself if it is unknown
+func Name(code string) string {
+	for _, c := range countries {
+		if c.Code == code {
+			return c.Name
+		}
+	}
+	return code
+}
+
+// Parse will split the given list of countries (separated by semicolon) and
+// return the unique ISO 3166 codes. countries that could not be matched
+// are returned separately with their original spelling
+func Parse(value string) (codes []string, unknown []string) {
+
+	codes = []string{}
+	unknown = []string{}
+
+	seen := make(map[string]bool)
+
+	for _, item := range strings.Split(value, ";") {
+
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		code, ok := Code(item)
+		if !ok {
+			code = item
+		}
+
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		if !ok {
+			unknown = append(unknown, item)
+			continue
+		}
+
+		codes = append(codes, code)
+	}
+
+	return codes, unknown
+}
+
+// List will return all countries of the given value as semicolon separated
+// list of ISO 3166 codes, countries that could not be matched are omitted (see
+// Unmatched)
+func List(value string) string {
+	codes, _ := Parse(value)
+	return strings.Join(codes, "; ")
+}
+
+// Unmatched will return all countries of the given value that could not be
+// matched to an ISO 3166 code with their original spelling (separated by
+// semicolon), i.e. to be reviewed by the curators
+func Unmatched(value string) string {
+	_, unknown := Parse(value)
+	return strings.Join(unknown, "; ")
+}
+
+// Summary will return the name of the country if all given countries are the
+// same and international if there are multiple countries
+func Summary(value string) string {
+	codes, unknown := Parse(value)
+	all := append(codes, unknown...)
+
+	switch len(all) {
+	case 0:
+		return ""
+	case 1:
+		return Name(all[0])
+	default:
+		return International
+	}
+}
+
+// normalize will convert the given name to lowercase and remove everything
+// but letters and digits
+func normalize(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
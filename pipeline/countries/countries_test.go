@@ -0,0 +1,62 @@
+package countries
+
+import "testing"
+
+func TestCode(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		code  string
+		found bool
+	}{
+		{"Switzerland", "CH", true},
+		{"switzerland", "CH", true},
+		{"Schweiz", "CH", true},
+		{"CH", "CH", true},
+		{"United States of America", "US", true},
+		{"U.S.", "US", true},
+		{"Korea, Republic of", "KR", true},
+		{"Iran (Islamic Republic of)", "IR", true},
+		{"England", "GB", true},
+		{"Atlantis", "", false},
+		{"", "", false},
+	}
+
+	for _, test := range tests {
+		code, found := Code(test.name)
+		if code != test.code || found != test.found {
+			t.Errorf("Code(%q) = %q, %v, expected %q, %v", test.name, code, found, test.code, test.found)
+		}
+	}
+}
+
+func TestListAndUnmatched(t *testing.T) {
+
+	tests := []struct {
+		value     string
+		list      string
+		unmatched string
+		summary   string
+	}{
+		{"", "", "", ""},
+		{"Switzerland", "CH", "", "Switzerland"},
+		{"Switzerland; Schweiz; CH", "CH", "", "Switzerland"},
+		{"United States; Germany", "US; DE", "", International},
+		{"Germany; Atlantis", "DE", "Atlantis", International},
+		{"Atlantis", "", "Atlantis", "Atlantis"},
+		{"Atlantis; Atlantis; Lemuria", "", "Atlantis; Lemuria", International},
+		{" ; France ;", "FR", "", "France"},
+	}
+
+	for _, test := range tests {
+		if list := List(test.value); list != test.list {
+			t.Errorf("List(%q) = %q, expected %q", test.value, list, test.list)
+		}
+		if unmatched := Unmatched(test.value); unmatched != test.unmatched {
+			t.Errorf("Unmatched(%q) = %q, expected %q", test.value, unmatched, test.unmatched)
+		}
+		if summary := Summary(test.value); summary != test.summary {
+			t.Errorf("Summary(%q) = %q, expected %q", test.value, summary, test.summary)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package countries
+
+// countries contains all ISO 3166-1 countries with their alpha-2 code, the
+// english short name and alternative spellings used by the registries
+var countries = []struct {
+	Code    string
+	Name    string
+	Aliases []string
+}{
+	{"AD", "Andorra", nil},
+	{"AE", "United Arab Emirates", nil},
+	{"AF", "Afghanistan", nil},
+	{"AG", "Antigua and Barbuda", nil},
+	{"AI", "Anguilla", nil},
+	{"AL", "Albania", nil},
+	{"AM", "Armenia", nil},
+	{"AO", "Angola", nil},
+	{"AQ", "Antarctica", nil},
+	{"AR", "Argentina", []string{"Argentinien"}},
+	{"AS", "American Samoa", nil},
+	{"AT", "Austria", []string{"Österreich"}},
+	{"AU", "Australia", []string{"Australien"}},
+	{"AW", "Aruba", nil},
+	{"AX", "Åland Islands", nil},
+	{"AZ", "Azerbaijan", nil},
+	{"BA", "Bosnia and Herzegovina", nil},
+	{"BB", "Barbados", nil},
+	{"BD", "Bangladesh", nil},
+	{"BE", "Belgium", []string{"Belgien"}},
+	{"BF", "Burkina Faso", nil},
+	{"BG", "Bulgaria", []string{"Bulgarien"}},
+	{"BH", "Bahrain", nil},
+	{"BI", "Burundi", nil},
+	{"BJ", "Benin", nil},
+	{"BL", "Saint Barthélemy", nil},
+	{"BM", "Bermuda", nil},
+	{"BN", "Brunei Darussalam", nil},
+	{"BO", "Bolivia", []string{"Bolivia, Plurinational State of"}},
+	{"BQ", "Bonaire, Sint Eustatius and Saba", nil},
+	{"BR", "Brazil", []string{"Brasilien"}},
+	{"BS", "Bahamas", nil},
+	{"BT", "Bhutan", nil},
+	{"BV", "Bouvet Island", nil},
+	{"BW", "Botswana", nil},
+	{"BY", "Belarus", []string{"Weissrussland", "Weißrussland"}},
+	{"BZ", "Belize", nil},
+	{"CA", "Canada", []string{"Kanada"}},
+	{"CC", "Cocos (Keeling) Islands", nil},
+	{"CD", "Congo, The Democratic Republic of the", []string{"Democratic Republic of the Congo", "Congo (Kinshasa)", "DR Congo"}},
+	{"CF", "Central African Republic", nil},
+	{"CG", "Congo", []string{"Republic of the Congo", "Congo (Brazzaville)"}},
+	{"CH", "Switzerland", []string{"Schweiz", "Suisse", "Svizzera"}},
+	{"CI", "Côte d'Ivoire", []string{"Cote d'Ivoire", "Ivory Coast"}},
+	{"CK", "Cook Islands", nil},
+	{"CL", "Chile", nil},
+	{"CM", "Cameroon", nil},
+	{"CN", "China", []string{"People's Republic of China", "China (Mainland)"}},
+	{"CO", "Colombia", []string{"Kolumbien"}},
+	{"CR", "Costa Rica", nil},
+	{"CU", "Cuba", nil},
+	{"CV", "Cape Verde", nil},
+	{"CW", "Curaçao", nil},
+	{"CX", "Christmas Island", nil},
+	{"CY", "Cyprus", nil},
+	{"CZ", "Czechia", []string{"Czech Republic", "Tschechien"}},
+	{"DE", "Germany", []string{"Deutschland", "Federal Republic of Germany"}},
+	{"DJ", "Djibouti", nil},
+	{"DK", "Denmark", []string{"Dänemark"}},
+	{"DM", "Dominica", nil},
+	{"DO", "Dominican Republic", nil},
+	{"DZ", "Algeria", nil},
+	{"EC", "Ecuador", nil},
+	{"EE", "Estonia", nil},
+	{"EG", "Egypt", []string{"Ägypten"}},
+	{"EH", "Western Sahara", nil},
+	{"ER", "Eritrea", nil},
+	{"ES", "Spain", []string{"Spanien"}},
+	{"ET", "Ethiopia", nil},
+	{"FI", "Finland", []string{"Finnland"}},
+	{"FJ", "Fiji", nil},
+	{"FK", "Falkland Islands (Malvinas)", nil},
+	{"FM", "Micronesia, Federated States of", nil},
+	{"FO", "Faroe Islands", nil},
+	{"FR", "France", []string{"Frankreich"}},
+	{"GA", "Gabon", nil},
+	{"GB", "United Kingdom", []string{"UK", "Great Britain", "England", "Scotland", "Wales", "Northern Ireland", "Grossbritannien", "Großbritannien", "Vereinigtes Königreich"}},
+	{"GD", "Grenada", nil},
+	{"GE", "Georgia", nil},
+	{"GF", "French Guiana", nil},
+	{"GG", "Guernsey", nil},
+	{"GH", "Ghana", nil},
+	{"GI", "Gibraltar", nil},
+	{"GL", "Greenland", nil},
+	{"GM", "Gambia", nil},
+	{"GN", "Guinea", nil},
+	{"GP", "Guadeloupe", nil},
+	{"GQ", "Equatorial Guinea", nil},
+	{"GR", "Greece", []string{"Griechenland"}},
+	{"GS", "South Georgia and the South Sandwich Islands", nil},
+	{"GT", "Guatemala", nil},
+	{"GU", "Guam", nil},
+	{"GW", "Guinea-Bissau", nil},
+	{"GY", "Guyana", nil},
+	{"HK", "Hong Kong", []string{"Hong Kong SAR", "Hong Kong, China"}},
+	{"HM", "Heard Island and McDonald Islands", nil},
+	{"HN", "Honduras", nil},
+	{"HR", "Croatia", []string{"Kroatien"}},
+	{"HT", "Haiti", nil},
+	{"HU", "Hungary", []string{"Ungarn"}},
+	{"ID", "Indonesia", nil},
+	{"IE", "Ireland", []string{"Irland"}},
+	{"IL", "Israel", nil},
+	{"IM", "Isle of Man", nil},
+	{"IN", "India", []string{"Indien"}},
+	{"IO", "British Indian Ocean Territory", nil},
+	{"IQ", "Iraq", nil},
+	{"IR", "Iran, Islamic Republic of", []string{"Iran (Islamic Republic of)", "Iran"}},
+	{"IS", "Iceland", nil},
+	{"IT", "Italy", []string{"Italien"}},
+	{"JE", "Jersey", nil},
+	{"JM", "Jamaica", nil},
+	{"JO", "Jordan", nil},
+	{"JP", "Japan", nil},
+	{"KE", "Kenya", nil},
+	{"KG", "Kyrgyzstan", nil},
+	{"KH", "Cambodia", nil},
+	{"KI", "Kiribati", nil},
+	{"KM", "Comoros", nil},
+	{"KN", "Saint Kitts and Nevis", nil},
+	{"KP", "Korea, Democratic People's Republic of", []string{"North Korea"}},
+	{"KR", "Korea, Republic of", []string{"South Korea", "Korea", "Republic of Korea", "Korea (South)", "Südkorea"}},
+	{"KW", "Kuwait", nil},
+	{"KY", "Cayman Islands", nil},
+	{"KZ", "Kazakhstan", nil},
+	{"LA", "Lao People's Democratic Republic", []string{"Laos"}},
+	{"LB", "Lebanon", nil},
+	{"LC", "Saint Lucia", nil},
+	{"LI", "Liechtenstein", nil},
+	{"LK", "Sri Lanka", nil},
+	{"LR", "Liberia", nil},
+	{"LS", "Lesotho", nil},
+	{"LT", "Lithuania", nil},
+	{"LU", "Luxembourg", []string{"Luxemburg"}},
+	{"LV", "Latvia", nil},
+	{"LY", "Libya", nil},
+	{"MA", "Morocco", nil},
+	{"MC", "Monaco", nil},
+	{"MD", "Moldova, Republic of", []string{"Moldova"}},
+	{"ME", "Montenegro", nil},
+	{"MF", "Saint Martin (French part)", nil},
+	{"MG", "Madagascar", nil},
+	{"MH", "Marshall Islands", nil},
+	{"MK", "North Macedonia", []string{"Macedonia", "The former Yugoslav Republic of Macedonia", "Macedonia, The Former Yugoslav Republic of"}},
+	{"ML", "Mali", nil},
+	{"MM", "Myanmar", nil},
+	{"MN", "Mongolia", nil},
+	{"MO", "Macao", []string{"Macau"}},
+	{"MP", "Northern Mariana Islands", nil},
+	{"MQ", "Martinique", nil},
+	{"MR", "Mauritania", nil},
+	{"MS", "Montserrat", nil},
+	{"MT", "Malta", nil},
+	{"MU", "Mauritius", nil},
+	{"MV", "Maldives", nil},
+	{"MW", "Malawi", nil},
+	{"MX", "Mexico", []string{"Mexiko"}},
+	{"MY", "Malaysia", nil},
+	{"MZ", "Mozambique", nil},
+	{"NA", "Namibia", nil},
+	{"NC", "New Caledonia", nil},
+	{"NE", "Niger", nil},
+	{"NF", "Norfolk Island", nil},
+	{"NG", "Nigeria", nil},
+	{"NI", "Nicaragua", nil},
+	{"NL", "Netherlands", []string{"Niederlande", "The Netherlands", "Holland"}},
+	{"NO", "Norway", []string{"Norwegen"}},
+	{"NP", "Nepal", nil},
+	{"NR", "Nauru", nil},
+	{"NU", "Niue", nil},
+	{"NZ", "New Zealand", []string{"Neuseeland"}},
+	{"OM", "Oman", nil},
+	{"PA", "Panama", nil},
+	{"PE", "Peru", nil},
+	{"PF", "French Polynesia", nil},
+	{"PG", "Papua New Guinea", nil},
+	{"PH", "Philippines", nil},
+	{"PK", "Pakistan", nil},
+	{"PL", "Poland", []string{"Polen"}},
+	{"PM", "Saint Pierre and Miquelon", nil},
+	{"PN", "Pitcairn", nil},
+	{"PR", "Puerto Rico", nil},
+	{"PS", "Palestine, State of", []string{"Palestine", "Palestinian Territory, occupied", "Palestinian Territories"}},
+	{"PT", "Portugal", nil},
+	{"PW", "Palau", nil},
+	{"PY", "Paraguay", nil},
+	{"QA", "Qatar", nil},
+	{"RE", "Réunion", nil},
+	{"RO", "Romania", []string{"Rumänien"}},
+	{"RS", "Serbia", nil},
+	{"RU", "Russian Federation", []string{"Russia", "Russland"}},
+	{"RW", "Rwanda", nil},
+	{"SA", "Saudi Arabia", []string{"Saudi-Arabien"}},
+	{"SB", "Solomon Islands", nil},
+	{"SC", "Seychelles", nil},
+	{"SD", "Sudan", nil},
+	{"SE", "Sweden", []string{"Schweden"}},
+	{"SG", "Singapore", nil},
+	{"SH", "Saint Helena", nil},
+	{"SI", "Slovenia", []string{"Slowenien"}},
+	{"SJ", "Svalbard and Jan Mayen", nil},
+	{"SK", "Slovakia", []string{"Slowakei"}},
+	{"SL", "Sierra Leone", nil},
+	{"SM", "San Marino", nil},
+	{"SN", "Senegal", nil},
+	{"SO", "Somalia", nil},
+	{"SR", "Suriname", nil},
+	{"SS", "South Sudan", nil},
+	{"ST", "Sao Tome and Principe", nil},
+	{"SV", "El Salvador", nil},
+	{"SX", "Sint Maarten (Dutch part)", nil},
+	{"SY", "Syrian Arab Republic", []string{"Syria"}},
+	{"SZ", "Eswatini", []string{"Swaziland"}},
+	{"TC", "Turks and Caicos Islands", nil},
+	{"TD", "Chad", nil},
+	{"TF", "French Southern Territories", nil},
+	{"TG", "Togo", nil},
+	{"TH", "Thailand", nil},
+	{"TJ", "Tajikistan", nil},
+	{"TK", "Tokelau", nil},
+	{"TL", "Timor-Leste", []string{"East Timor"}},
+	{"TM", "Turkmenistan", nil},
+	{"TN", "Tunisia", nil},
+	{"TO", "Tonga", nil},
+	{"TR", "Turkey", []string{"Türkei", "Turkiye", "Türkiye"}},
+	{"TT", "Trinidad and Tobago", nil},
+	{"TV", "Tuvalu", nil},
+	{"TW", "Taiwan", []string{"Taiwan, Province of China", "Chinese Taipei", "Republic of China"}},
+	{"TZ", "Tanzania", []string{"Tanzania, United Republic of", "United Republic of Tanzania"}},
+	{"UA", "Ukraine", nil},
+	{"UG", "Uganda", nil},
+	{"UM", "United States Minor Outlying Islands", nil},
+	{"US", "United States", []string{"USA", "United States of America", "U.S.", "US", "Vereinigte Staaten", "Vereinigte Staaten von Amerika"}},
+	{"UY", "Uruguay", nil},
+	{"UZ", "Uzbekistan", nil},
+	{"VA", "Holy See (Vatican City State)", []string{"Vatican City", "Holy See"}},
+	{"VC", "Saint Vincent and the Grenadines", nil},
+	{"VE", "Venezuela", []string{"Venezuela, Bolivarian Republic of"}},
+	{"VG", "Virgin Islands, British", nil},
+	{"VI", "Virgin Islands, U.S.", nil},
+	{"VN", "Viet Nam", []string{"Vietnam"}},
+	{"VU", "Vanuatu", nil},
+	{"WF", "Wallis and Futuna", nil},
+	{"WS", "Samoa", nil},
+	{"YE", "Yemen", nil},
+	{"YT", "Mayotte", nil},
+	{"ZA", "South Africa", []string{"Südafrika"}},
+	{"ZM", "Zambia", nil},
+	{"ZW", "Zimbabwe", nil},
+}
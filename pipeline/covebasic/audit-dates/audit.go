@@ -0,0 +1,146 @@
+package main
+
+import (
+	"time"
+
+	"dkfbasel.ch/covid-evidence/helpers"
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+const (
+	actionFix    = "fix"
+	actionReview = "review"
+)
+
+// finding describes an issue with a date field of a covebasic record and the
+// fix proposed for it
+type finding struct {
+	ID        int    `json:"id"`
+	Source    string `json:"source"`
+	SourceID  string `json:"source_id"`
+	Field     string `json:"field"`
+	Current   string `json:"current"`
+	Proposed  string `json:"proposed"`
+	Certainty string `json:"certainty"`
+	Generated bool   `json:"generated"`
+	Issue     string `json:"issue"`
+	Action    string `json:"action"`
+}
+
+// auditRecord will check all date fields of the given covebasic record against
+// the screening record of its source and check the dates for impossible orderings
+func auditRecord(r *ninox.Record, screening *ninox.Record, today time.Time) []finding {
+
+	findings := []finding{}
+
+	source := r.Field("source")
+
+	// keep track of the values after applying the proposed fixes, so that
+	// the ordering is checked on the resulting values
+	values := make(map[string]string)
+
+	for _, field := range dateFields {
+
+		current := r.Field(field.Name)
+		values[field.Name] = current
+
+		// nothing to compare if there is no screening record or source field
+		names := field.Sources[source]
+		if screening == nil || len(names) == 0 {
+			continue
+		}
+
+		// use the first source field with a value
+		value := ""
+		for _, name := range names {
+			value = screening.Field(name)
+			if value != "" {
+				break
+			}
+		}
+
+		transform := field.Transform
+		if transform == nil {
+			transform = helpers.ToIsoDate
+		}
+
+		transformed, generated := transform(value)
+		fromSource := helpers.AsString(transformed)
+
+		if current == fromSource {
+			continue
+		}
+
+		// skip items that are empty in the source
+		if fromSource == "" {
+			continue
+		}
+
+		f := newFinding(r, field.Name, "differs from source")
+		f.Proposed = fromSource
+		f.Generated = generated
+
+		// values set or verified by humans are never changed automatically
+		if isHumanCertainty(f.Certainty) {
+			f.Action = actionReview
+		} else {
+			values[field.Name] = fromSource
+		}
+
+		findings = append(findings, f)
+	}
+
+	start, hasStart := helpers.ParseIsoDate(values["start_date"])
+	end, hasEnd := helpers.ParseIsoDate(values["end_date"])
+	status, hasStatus := helpers.ParseIsoDate(values["status_date"])
+	expected, hasExpected := helpers.ParseIsoDate(values["results_expected_date"])
+
+	if hasStart && hasEnd && isBefore(values["end_date"], end, values["start_date"], start) {
+		f := newFinding(r, "end_date", "end date before start date")
+		f.Action = actionReview
+		findings = append(findings, f)
+	}
+
+	if hasEnd && hasExpected && isBefore(values["results_expected_date"], expected, values["end_date"], end) {
+		f := newFinding(r, "results_expected_date", "results expected before end date")
+		f.Action = actionReview
+		findings = append(findings, f)
+	}
+
+	if hasStatus && status.After(today) {
+		f := newFinding(r, "status_date", "status date in the future")
+		f.Action = actionReview
+		findings = append(findings, f)
+	}
+
+	return findings
+}
+
+// newFinding will initialize a new finding for the given record and field
+func newFinding(r *ninox.Record, field string, issue string) finding {
+	return finding{
+		ID:        r.ID,
+		Source:    r.Field("source"),
+		SourceID:  r.Field("source_id"),
+		Field:     field,
+		Current:   r.Field(field),
+		Certainty: r.Field(field + "_certainty"),
+		Issue:     issue,
+		Action:    actionFix,
+	}
+}
+
+// isBefore will check if date a is before date b. dates with month precision
+// are only compared on the month
+func isBefore(aValue string, a time.Time, bValue string, b time.Time) bool {
+	if len(aValue) != len(bValue) {
+		a = time.Date(a.Year(), a.Month(), 1, 0, 0, 0, 0, time.UTC)
+		b = time.Date(b.Year(), b.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return a.Before(b)
+}
+
+// isHumanCertainty will check if the given certainty was set by a human
+func isHumanCertainty(certainty string) bool {
+	return certainty == "human" || certainty == "verified"
+}
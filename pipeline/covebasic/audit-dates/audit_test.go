@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+func TestAuditResultsExpectedDate(t *testing.T) {
+
+	today := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		source     string
+		current    string
+		certainty  string
+		completion string
+		completed  string
+		proposed   string
+		action     string
+	}{
+		{"matches source", "clinicaltrials.gov", "2021-05-30", "generated", "May 30, 2020", "", "", ""},
+		{"differs from source", "clinicaltrials.gov", "2021-01-01", "generated", "May 30, 2020", "", "2021-05-30", actionFix},
+		{"missing", "clinicaltrials.gov", "", "prefilled", "December 2020", "", "2021-12", actionFix},
+		{"set by human", "ICTRP", "2021-01-01", "human", "2020-05-30", "", "2021-05-30", actionReview},
+		{"no completion date", "clinicaltrials.gov", "2021-01-01", "generated", "", "", "", ""},
+		{"primary completion preferred", "clinicaltrials.gov", "2021-05-30", "generated", "May 30, 2020", "June 30, 2020", "", ""},
+		{"completion date as fallback", "clinicaltrials.gov", "2021-01-01", "generated", "", "June 30, 2020", "2021-06-30", actionFix},
+		{"fallback matches", "clinicaltrials.gov", "2021-06-30", "generated", "", "June 30, 2020", "", ""},
+	}
+
+	for _, test := range tests {
+
+		r := &ninox.Record{ID: 1, Fields: map[string]interface{}{
+			"source":                          test.source,
+			"results_expected_date":           test.current,
+			"results_expected_date_certainty": test.certainty,
+		}}
+		screening := &ninox.Record{Fields: map[string]interface{}{
+			"date_primary_completed": test.completion,
+			"date_completed":         test.completed,
+			"results date completed": test.completion,
+		}}
+
+		var found *finding
+		for _, f := range auditRecord(r, screening, today) {
+			if f.Field == "results_expected_date" {
+				f := f
+				found = &f
+			}
+		}
+
+		if test.action == "" {
+			if found != nil {
+				t.Errorf("%s: unexpected finding %+v", test.name, *found)
+			}
+			continue
+		}
+
+		if found == nil {
+			t.Errorf("%s: no finding", test.name)
+			continue
+		}
+		if found.Proposed != test.proposed || found.Action != test.action {
+			t.Errorf("%s: proposed %q (%s), expected %q (%s)", test.name,
+				found.Proposed, found.Action, test.proposed, test.action)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import "dkfbasel.ch/covid-evidence/helpers"
+
+// dateFields lists all date fields of covebasic together with the
+// corresponding fields in the screening table of each source and the
+// conversion of the source value (helpers.ToIsoDate if not set). the first
+// field with a value is used, as in the imports of the sources
+var dateFields = []struct {
+	Name      string
+	Sources   map[string][]string
+	Transform func(value string) (interface{}, bool)
+}{
+	{"status_date", map[string][]string{
+		"clinicaltrials.gov":     {"date_last_update_posted"},
+		"ICTRP":                  {"Last Refreshed On"},
+		"medRxiv":                {"rel_date"},
+		"Ethics committees (CH)": {"Date final decision"},
+	}, nil},
+	{"start_date", map[string][]string{
+		"clinicaltrials.gov": {"date_started"},
+		"ICTRP":              {"Date enrollement"},
+	}, nil},
+	{"end_date", map[string][]string{
+		"clinicaltrials.gov": {"date_completed"},
+	}, nil},
+	{"results_expected_date", map[string][]string{
+		"clinicaltrials.gov": {"date_primary_completed", "date_completed"},
+		"ICTRP":              {"results date completed"},
+	}, helpers.ToResultsExpectedDate},
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+
+	"dkfbasel.ch/covid-evidence/ninox"
+	"dkfbasel.ch/covid-evidence/review"
+)
+
+func main() {
+
+	command := "plan"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	var err error
+
+	switch command {
+	case "plan":
+		err = plan()
+	case "apply":
+		if len(os.Args) < 3 {
+			log.Fatalln("usage: audit-dates apply <plan-file>")
+		}
+		err = apply(os.Args[2])
+	default:
+		log.Fatalln("usage: audit-dates [plan|apply <plan-file>]")
+	}
+
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+
+}
+
+// plan will compare all covebasic date fields with the sources and write the
+// findings and proposed fixes into a plan file
+func plan() error {
+
+	log.Println("fetching records")
+
+	records, err := ninox.FetchRecords(ninox.CoveBasicURL, "")
+	if err != nil {
+		return fmt.Errorf("could not fetch covebasic records: %w", err)
+	}
+
+	// index the screening records of all sources by source and source id
+	screeningIndex := make(map[string]map[string]*ninox.Record)
+
+	for _, source := range ninox.Sources {
+
+		screening, err := ninox.FetchRecords(source.URL, "")
+		if err != nil {
+			return fmt.Errorf("could not fetch %s records: %w", source.Name, err)
+		}
+
+		fmt.Printf("got %d records from %s\n", len(screening), source.Name)
+
+		index := make(map[string]*ninox.Record)
+		for i, r := range screening {
+			index[r.Field(source.IDField)] = &screening[i]
+		}
+		screeningIndex[source.Name] = index
+	}
+
+	today := time.Now()
+
+	findings := []finding{}
+	actionCounter := make(map[string]int)
+
+	for i, r := range records {
+
+		var screening *ninox.Record
+		index, ok := screeningIndex[r.Field("source")]
+		if ok {
+			screening = index[r.Field("source_id")]
+		}
+
+		for _, f := range auditRecord(&records[i], screening, today) {
+
+			fmt.Printf("% 6d: % 16s: % 22s: % 10s -> % 10s: %s (%s)\n",
+				f.ID, f.SourceID, f.Field, f.Current, f.Proposed, f.Issue, f.Action)
+
+			actionCounter[f.Action]++
+			findings = append(findings, f)
+		}
+	}
+
+	for key, value := range actionCounter {
+		fmt.Printf("Count: %03d, Action: %s\n", value, key)
+	}
+
+	fileName := fmt.Sprintf("dates_%s--plan.json", today.Format("2006-01-02-150405"))
+
+	payload, err := json.MarshalIndent(findings, "", "\t")
+	if err != nil {
+		return fmt.Errorf("could not encode plan: %w", err)
+	}
+
+	err = ioutil.WriteFile(fileName, payload, 0644)
+	if err != nil {
+		return fmt.Errorf("could not write plan: %w", err)
+	}
+
+	fmt.Printf("plan written to %s\n", fileName)
+
+	return nil
+}
+
+// apply will apply all fixes from the given plan file to covebasic, skipping
+// all records that have changed since the plan was created
+func apply(fileName string) error {
+
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return fmt.Errorf("could not read plan: %w", err)
+	}
+
+	var findings []finding
+	err = json.Unmarshal(content, &findings)
+	if err != nil {
+		return fmt.Errorf("could not parse plan: %w", err)
+	}
+
+	log.Println("fetching records")
+
+	records, err := ninox.FetchRecords(ninox.CoveBasicURL, "")
+	if err != nil {
+		return fmt.Errorf("could not fetch covebasic records: %w", err)
+	}
+
+	index := make(map[int]*ninox.Record)
+	for i, r := range records {
+		index[r.ID] = &records[i]
+	}
+
+	updates := make(map[int]*ninox.Record)
+
+	for _, f := range findings {
+
+		if f.Action != actionFix {
+			continue
+		}
+
+		current, ok := index[f.ID]
+		if !ok {
+			fmt.Printf("% 6d: record does not exist anymore\n", f.ID)
+			continue
+		}
+
+		// only prefilled records are changed automatically (see review/review.go)
+		if !review.Automated(current.Field("review_status")) {
+			fmt.Printf("% 6d: review status %q does not allow changes\n", f.ID, current.Field("review_status"))
+			continue
+		}
+
+		// the value must not have changed since the plan was created
+		if current.Field(f.Field) != f.Current ||
+			current.Field(f.Field+"_certainty") != f.Certainty {
+			fmt.Printf("% 6d: %s has changed since the plan was created\n", f.ID, f.Field)
+			continue
+		}
+
+		r, ok := updates[f.ID]
+		if !ok {
+			r = &ninox.Record{}
+			r.ID = f.ID
+			r.Fields = make(map[string]interface{})
+			updates[f.ID] = r
+		}
+
+		r.Fields[f.Field] = f.Proposed
+		if f.Generated {
+			r.Fields[f.Field+"_certainty"] = "generated"
+		} else {
+			r.Fields[f.Field+"_certainty"] = "prefilled"
+		}
+	}
+
+	updated := []*ninox.Record{}
+	for _, r := range updates {
+		updated = append(updated, r)
+	}
+
+	fmt.Printf("updates for %d records\n", len(updated))
+
+	if len(updated) == 0 {
+		return nil
+	}
+
+	var action string
+	fmt.Printf("Perform operation [n]: ")
+	fmt.Scanln(&action)
+
+	if action != "y" && action != "yes" {
+		return nil
+	}
+
+	return ninox.UpdateRecords(ninox.CoveBasicURL, updated)
+}
@@ -3,8 +3,11 @@ package main
 import (
 	"fmt"
 	"log"
+	"time"
 
+	"dkfbasel.ch/covid-evidence/helpers"
 	"dkfbasel.ch/covid-evidence/ninox"
+	"dkfbasel.ch/covid-evidence/rules"
 )
 
 func main() {
@@ -38,33 +41,31 @@ func main() {
 
 	// find all items to exclude
 	var exclude []*ninox.Record
+	excludedBy := make(map[string]int)
+
+	currentDate := time.Now().Format("2006-01-02")
+	operator := helpers.Operator()
 
 	for i, record := range basicRecords {
 
-		// skip all non covid items
-		if record.Field("is_covid") == "no" {
-			exclude = append(exclude, &basicRecords[i])
+		// check the exclusion rules (see rules/config.go)
+		rule, ok := rules.Excluded(record.Field)
+		if !ok {
 			continue
 		}
 
-		// skip all non trial items
-		isTrial := record.Field("is_trial")
-		isObservational := record.Field("is_observational")
-		if isTrial == "no" {
-
-			switch isObservational {
-			case "no", "unclear", "yes":
-				exclude = append(exclude, &basicRecords[i])
-				continue
-			}
-
-		}
+		// annotate the record with the rule that excluded it
+		basicRecords[i].Fields["exclusion_rule"] = rule.Name
+		basicRecords[i].Fields["exclusion_reason"] = rule.Description
+		basicRecords[i].Fields["exclusion_date"] = currentDate
+		basicRecords[i].Fields["exclusion_rule_version"] = rules.Version
+		basicRecords[i].Fields["exclusion_operator"] = operator
+		exclude = append(exclude, &basicRecords[i])
+		excludedBy[rule.Name]++
+	}
 
-		// skip all duplicates
-		if record.Field("is_duplicate") == "true" {
-			exclude = append(exclude, &basicRecords[i])
-			continue
-		}
+	for name, count := range excludedBy {
+		log.Printf("Excluded by %s: %d", name, count)
 	}
 
 	// initialize a list of records ids to be deleted from the basic table
@@ -90,10 +91,16 @@ func main() {
 
 	if action == "y" || action == "yes" {
 		// import the exlusion records into the exlusion table
-		ninox.UpdateRecords(ninox.CoveBasicExlusionURL, exclude)
+		err := ninox.UpdateRecords(ninox.CoveBasicExlusionURL, exclude)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
 
 		// delete the corresponding records from the basic table
-		ninox.DeleteRecords(ninox.CoveBasicURL, recordsToDelete)
+		err = ninox.DeleteRecords(ninox.CoveBasicURL, recordsToDelete)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
 	}
 
 }
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"dkfbasel.ch/covid-evidence/ninox"
+	"dkfbasel.ch/covid-evidence/rules"
+)
+
+// unknown is used for exclusions without recorded reason or date (i.e.
+// records that were moved before the reasons were recorded)
+const unknown = "unknown"
+
+// main will summarize the records in the exclusions table by reason and month
+// of the exclusion. the report contains the number of exclusions and of records
+// moved back to covebasic per month and the cumulative number of records
+// excluded at the end of the month, i.e. for the flow numbers of the living
+// review
+func main() {
+
+	log.Println("fetching records")
+
+	records, err := ninox.FetchRecords(ninox.CoveBasicExlusionURL, "")
+	if err != nil {
+		fmt.Printf("could not fetch exclusion records: %+v\n", err)
+		return
+	}
+
+	// records moved back to covebasic before the inclusion date was written to
+	// the exclusions are identified by their copy in covebasic
+	basicRecords, err := ninox.FetchRecords(ninox.CoveBasicURL, "")
+	if err != nil {
+		fmt.Printf("could not fetch covebasic records: %+v\n", err)
+		return
+	}
+
+	c := count(records, inclusionDates(basicRecords))
+	reasons := c.reasons
+
+	// order the reasons as defined in the rules, unknown reasons at the end
+	reasonOrder := []string{}
+	for _, rule := range rules.Exclusion {
+		if reasons[rule.Name] {
+			reasonOrder = append(reasonOrder, rule.Name)
+			delete(reasons, rule.Name)
+		}
+	}
+	others := []string{}
+	for reason := range reasons {
+		others = append(others, reason)
+	}
+	sort.Strings(others)
+	reasonOrder = append(reasonOrder, others...)
+
+	// unknown months are reported first, since they precede the recorded dates
+	months := []string{}
+	for month := range c.months {
+		months = append(months, month)
+	}
+	sort.Slice(months, func(i, j int) bool {
+		if months[i] == unknown || months[j] == unknown {
+			return months[i] == unknown && months[j] != unknown
+		}
+		return months[i] < months[j]
+	})
+
+	fileName := fmt.Sprintf("exclusions_%s.csv", time.Now().Format("2006-01-02"))
+	file, err := os.Create(fileName)
+	if err != nil {
+		fmt.Printf("could not create report: %+v\n", err)
+		return
+	}
+	defer file.Close() // nolint:errcheck
+
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+
+	// nolint:errcheck
+	writer.Write([]string{"month", "reason", "description", "excluded", "restored", "excluded_cumulative"})
+
+	cumulative := make(map[string]int)
+	for _, month := range months {
+		for _, reason := range reasonOrder {
+			excluded := c.excluded[month][reason]
+			restored := c.restored[month][reason]
+			cumulative[reason] += excluded - restored
+			if excluded == 0 && restored == 0 {
+				continue
+			}
+
+			// nolint:errcheck
+			writer.Write([]string{month, reason, description(reason),
+				fmt.Sprintf("%d", excluded), fmt.Sprintf("%d", restored),
+				fmt.Sprintf("%d", cumulative[reason])})
+		}
+	}
+
+	writer.Flush()
+	file.Close() // nolint:errcheck
+
+	fmt.Printf("exclusions: %d, moved back to covebasic: %d (see %s)\n",
+		len(records)-c.restoredTotal, c.restoredTotal, fileName)
+	for _, reason := range reasonOrder {
+		fmt.Printf("%-12s %d\n", reason+":", c.totals[reason])
+	}
+}
+
+// counts contains the number of exclusions by month and reason
+type counts struct {
+	excluded map[string]map[string]int
+	restored map[string]map[string]int
+	months   map[string]bool
+	reasons  map[string]bool
+
+	// records currently excluded per reason
+	totals        map[string]int
+	restoredTotal int
+}
+
+// inclusionDates will return the inclusion date of all records in covebasic by
+// their key. records without inclusion date use the date they were created
+func inclusionDates(basicRecords []ninox.Record) map[string]string {
+	dates := make(map[string]string)
+	for _, r := range basicRecords {
+		date := r.Field("inclusion_date")
+		if date == "" {
+			date = r.CreatedAt
+		}
+		dates[r.Key()] = date
+	}
+	return dates
+}
+
+// count will count the exclusions by month and reason. records moved back to
+// covebasic are counted as excluded in the month of the exclusion and as
+// restored in the month of the inclusion. the inclusion date of records that
+// are also in covebasic is taken from covebasic if it is missing
+func count(records []ninox.Record, inCoveBasic map[string]string) counts {
+
+	c := counts{
+		excluded: make(map[string]map[string]int),
+		restored: make(map[string]map[string]int),
+		months:   make(map[string]bool),
+		reasons:  make(map[string]bool),
+		totals:   make(map[string]int),
+	}
+
+	add := func(list map[string]map[string]int, month string, reason string) {
+		if list[month] == nil {
+			list[month] = make(map[string]int)
+		}
+		list[month][reason]++
+		c.months[month] = true
+	}
+
+	for _, r := range records {
+
+		reason := r.Field("exclusion_rule")
+		if reason == "" {
+			reason = unknown
+		}
+
+		add(c.excluded, month(r.Field("exclusion_date")), reason)
+		c.reasons[reason] = true
+
+		field := r.Field
+		if date, ok := inCoveBasic[r.Key()]; ok && r.Field("inclusion_date") == "" {
+			field = func(name string) string {
+				if name == "inclusion_date" {
+					return date
+				}
+				return r.Field(name)
+			}
+		}
+
+		if rules.Restored(field) {
+			add(c.restored, month(field("inclusion_date")), reason)
+			c.restoredTotal++
+			continue
+		}
+
+		c.totals[reason]++
+	}
+
+	return c
+}
+
+// month will return the month of the given date, dates without month are
+// returned as unknown
+func month(date string) string {
+	if len(date) < len("2006-01") {
+		return unknown
+	}
+	return date[:len("2006-01")]
+}
+
+// description will return the description of the exclusion rule
+func description(reason string) string {
+	for _, rule := range rules.Exclusion {
+		if rule.Name == reason {
+			return rule.Description
+		}
+	}
+	return ""
+}
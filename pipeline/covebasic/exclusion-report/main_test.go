@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+func TestCount(t *testing.T) {
+
+	exclusion := func(rule string, excluded string, included string) ninox.Record {
+		return ninox.Record{Fields: map[string]interface{}{
+			"source": "clinicaltrials.gov", "source_id": rule + excluded,
+			"exclusion_rule": rule, "exclusion_date": excluded, "inclusion_date": included}}
+	}
+
+	records := []ninox.Record{
+		exclusion("not-trial", "2020-04-10", ""),
+		exclusion("not-trial", "2020-04-20", ""),
+		// moved back to covebasic
+		exclusion("not-trial", "2020-04-15", "2020-06-01"),
+		// moved back and excluded again
+		exclusion("not-covid", "2020-07-01", "2020-06-01"),
+		exclusion("", "", ""),
+		// moved back before the inclusion date was written to the exclusions
+		exclusion("not-trial", "2020-05-01", ""),
+	}
+
+	inCoveBasic := map[string]string{"clinicaltrials.gov::not-trial2020-05-01": "2020-06-10T08:00:00"}
+
+	c := count(records, inCoveBasic)
+
+	numbers := []struct {
+		name  string
+		value int
+		want  int
+	}{
+		{"excluded 2020-04", c.excluded["2020-04"]["not-trial"], 3},
+		{"excluded 2020-05", c.excluded["2020-05"]["not-trial"], 1},
+		{"restored 2020-06", c.restored["2020-06"]["not-trial"], 2},
+		{"excluded 2020-07", c.excluded["2020-07"]["not-covid"], 1},
+		{"restored not-covid", c.restored["2020-06"]["not-covid"], 0},
+		{"excluded unknown", c.excluded[unknown][unknown], 1},
+		{"total not-trial", c.totals["not-trial"], 2},
+		{"total not-covid", c.totals["not-covid"], 1},
+		{"restored", c.restoredTotal, 2},
+	}
+
+	for _, n := range numbers {
+		if n.value != n.want {
+			t.Errorf("%s: %d, expected %d", n.name, n.value, n.want)
+		}
+	}
+
+	if !c.months["2020-06"] || !c.reasons["not-trial"] || !c.reasons[unknown] {
+		t.Errorf("months %v or reasons %v are incomplete", c.months, c.reasons)
+	}
+}
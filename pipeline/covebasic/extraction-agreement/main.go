@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"dkfbasel.ch/covid-evidence/extraction"
+	"dkfbasel.ch/covid-evidence/ninox"
+	"dkfbasel.ch/covid-evidence/review"
+)
+
+func main() {
+
+	command := "report"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	var err error
+
+	switch command {
+	case "report":
+		err = report()
+	case "assign":
+		if len(os.Args) < 5 {
+			log.Fatalln("usage: extraction-agreement assign <count> <extractor-1> <extractor-2>")
+		}
+		count, convErr := strconv.Atoi(os.Args[2])
+		if convErr != nil || count <= 0 {
+			log.Fatalf("invalid count: %s", os.Args[2])
+		}
+		err = assign(count, os.Args[3], os.Args[4])
+	default:
+		log.Fatalln("usage: extraction-agreement [report|assign <count> <extractor-1> <extractor-2>]")
+	}
+
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+
+}
+
+// assign will select a random subset of covebasic records that were not yet
+// extracted and create two empty extraction records for the given extractors
+func assign(count int, first string, second string) error {
+
+	log.Println("fetching records")
+
+	records, err := ninox.FetchRecords(ninox.CoveBasicURL, "")
+	if err != nil {
+		return fmt.Errorf("could not fetch covebasic records: %w", err)
+	}
+
+	extractions, err := ninox.FetchRecords(ninox.CoveBasicExtractionURL, "")
+	if err != nil {
+		return fmt.Errorf("could not fetch extraction records: %w", err)
+	}
+
+	assigned := make(map[string]bool)
+	for _, r := range extractions {
+		assigned[r.Field(extraction.FieldCoveBasicID)] = true
+	}
+
+	// only records still to be extracted can be extracted independently
+	candidates := []*ninox.Record{}
+	for i, r := range records {
+		if assigned[strconv.Itoa(r.ID)] {
+			continue
+		}
+		if !review.Automated(r.Field("review_status")) {
+			continue
+		}
+		candidates = append(candidates, &records[i])
+	}
+
+	rand.Seed(time.Now().UnixNano())
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	if count > len(candidates) {
+		count = len(candidates)
+	}
+	candidates = candidates[:count]
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].ID < candidates[j].ID })
+
+	inserts := []*ninox.Record{}
+	for _, r := range candidates {
+
+		fmt.Printf("% 6d: %s\n", r.ID, r.Key())
+
+		for i, extractor := range []string{first, second} {
+			e := ninox.Record{}
+			e.Fields = make(map[string]interface{})
+			e.Fields[extraction.FieldCoveBasicID] = r.ID
+			e.Fields[extraction.FieldExtraction] = strconv.Itoa(i + 1)
+			e.Fields[extraction.FieldExtractor] = extractor
+			e.Fields["source"] = r.Field("source")
+			e.Fields["source_id"] = r.Field("source_id")
+			inserts = append(inserts, &e)
+		}
+	}
+
+	fmt.Printf("records to extract independently: %d (of %d candidates)\n", len(candidates), len(records))
+
+	if len(inserts) == 0 {
+		return nil
+	}
+
+	var action string
+	fmt.Printf("Perform operation [n]: ")
+	fmt.Scanln(&action) // nolint:errcheck
+
+	if action != "y" && action != "yes" {
+		return nil
+	}
+
+	return ninox.UpdateRecords(ninox.CoveBasicExtractionURL, inserts)
+}
+
+// report will compute the agreement of the two extractions per field and
+// write the agreement and a list of all disagreements to reconcile
+func report() error {
+
+	log.Println("fetching records")
+
+	extractions, err := ninox.FetchRecords(ninox.CoveBasicExtractionURL, "")
+	if err != nil {
+		return fmt.Errorf("could not fetch extraction records: %w", err)
+	}
+
+	records, err := ninox.FetchRecords(ninox.CoveBasicURL, "")
+	if err != nil {
+		return fmt.Errorf("could not fetch covebasic records: %w", err)
+	}
+
+	index := make(map[int]*ninox.Record)
+	for i, r := range records {
+		index[r.ID] = &records[i]
+	}
+
+	pairs, incomplete, multiple := extraction.Pairs(extractions)
+
+	currentDate := time.Now().Format("2006-01-02")
+
+	agreements := []extraction.Agreement{}
+	disagreements := []extraction.Disagreement{}
+
+	for _, field := range extraction.Fields {
+		agreement, list := extraction.Compare(field, pairs)
+		agreements = append(agreements, agreement)
+		disagreements = append(disagreements, list...)
+	}
+
+	// write the agreement per field
+	agreementFile := fmt.Sprintf("agreement_%s.csv", currentDate)
+	err = writeCSV(agreementFile,
+		[]string{"field", "pairs", "agreed", "percent_agreement", "kappa"},
+		func(write func(row ...string)) {
+			for _, a := range agreements {
+				kappa := ""
+				if a.KappaDefined {
+					kappa = fmt.Sprintf("%.3f", a.Kappa)
+				}
+				write(a.Field, strconv.Itoa(a.Pairs), strconv.Itoa(a.Agreed),
+					fmt.Sprintf("%.1f", a.Percent()), kappa)
+			}
+		})
+	if err != nil {
+		return err
+	}
+
+	// write the disagreements ordered by record to reconcile them
+	sort.SliceStable(disagreements, func(i, j int) bool {
+		return disagreements[i].Pair.CoveBasicID < disagreements[j].Pair.CoveBasicID
+	})
+
+	reconciliationFile := fmt.Sprintf("reconciliation_%s.csv", currentDate)
+	err = writeCSV(reconciliationFile,
+		[]string{"covebasic_id", "source", "source_id", "field",
+			"extraction_1", "extractor_1", "extraction_2", "extractor_2", "covebasic"},
+		func(write func(row ...string)) {
+			for _, d := range disagreements {
+				current := ""
+				if r, ok := index[d.Pair.CoveBasicID]; ok {
+					current = r.Field(d.Field)
+				}
+				write(strconv.Itoa(d.Pair.CoveBasicID),
+					d.Pair.First.Field("source"), d.Pair.First.Field("source_id"), d.Field,
+					d.First, d.Pair.First.Field(extraction.FieldExtractor),
+					d.Second, d.Pair.Second.Field(extraction.FieldExtractor), current)
+			}
+		})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("extracted independently: %d records, incomplete: %d\n", len(pairs), len(incomplete))
+	if len(multiple) > 0 {
+		fmt.Printf("more than two extractions (not compared): %v\n", multiple)
+	}
+	for _, a := range agreements {
+		kappa := "-"
+		if a.KappaDefined {
+			kappa = fmt.Sprintf("%.3f", a.Kappa)
+		}
+		fmt.Printf("%-24s pairs: %4d, agreement: %5.1f%%, kappa: %s\n",
+			a.Field+":", a.Pairs, a.Percent(), kappa)
+	}
+	fmt.Printf("disagreements: %d (see %s and %s)\n", len(disagreements), agreementFile, reconciliationFile)
+
+	return nil
+}
+
+// writeCSV will write the header and the rows added by the given function
+// into a csv file
+func writeCSV(fileName string, header []string, rows func(write func(row ...string))) error {
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %w", fileName, err)
+	}
+	defer file.Close() // nolint:errcheck
+
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+
+	// nolint:errcheck
+	writer.Write(header)
+
+	rows(func(row ...string) {
+		writer.Write(row) // nolint:errcheck
+	})
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("could not write %s: %w", fileName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"dkfbasel.ch/covid-evidence/duplicates"
+	"dkfbasel.ch/covid-evidence/ninox"
+	"dkfbasel.ch/covid-evidence/registries"
+)
+
+// threshold is the minimum score for records to be suggested as duplicates
+const threshold = 0.75
+
+// main will search covebasic for records that are likely duplicates and
+// write the suggestions into the duplicate_suggestion field for the curators
+// to confirm. suggestions of previous runs that are not found anymore are
+// cleared. records are never marked as duplicates or deleted automatically
+func main() {
+
+	log.Println("fetching records")
+
+	records, err := ninox.FetchRecords(ninox.CoveBasicURL, "")
+	if err != nil {
+		fmt.Printf("could not fetch covebasic records: %+v\n", err)
+		return
+	}
+
+	// index the screening records of all sources to access secondary ids
+	// and sponsors
+	screeningIndex := make(map[string]map[string]*ninox.Record)
+
+	for _, source := range ninox.Sources {
+
+		screening, err := ninox.FetchRecords(source.URL, "")
+		if err != nil {
+			fmt.Printf("could not fetch %s records: %+v\n", source.Name, err)
+			return
+		}
+
+		index := make(map[string]*ninox.Record)
+		for i, r := range screening {
+			index[r.Field(source.IDField)] = &screening[i]
+		}
+		screeningIndex[source.Name] = index
+	}
+
+	candidates := []*duplicates.Candidate{}
+
+	for _, r := range records {
+
+		// records already marked as duplicates are not considered, their
+		// suggestions are cleared (see suggestionUpdates)
+		if r.Field("is_duplicate") == "true" {
+			continue
+		}
+
+		c := duplicates.Candidate{
+			Key:     r.Key(),
+			ID:      r.ID,
+			IDs:     append([]string{r.Field("source_id")}, registries.Split(r.Field("registry_ids"))...),
+			Title:   r.Field("title"),
+			Sponsor: r.Field("funding"),
+		}
+		c.Enrollment, _ = strconv.Atoi(r.Field("n_enrollment"))
+
+		source, ok := ninox.SourceByName(r.Field("source"))
+		if ok {
+			s, ok := screeningIndex[source.Name][r.Field("source_id")]
+			if ok {
+				c.IDs = append(c.IDs, registries.Extract(source.Field(s, "secondary_ids"))...)
+				if c.Sponsor == "" {
+					c.Sponsor = source.Field(s, "sponsor")
+				}
+			}
+		}
+
+		candidates = append(candidates, &c)
+	}
+
+	clusters := duplicates.Find(candidates, threshold)
+
+	fmt.Printf("found %d clusters of possible duplicates\n", len(clusters))
+
+	// write all clusters to a csv file for review
+	fileName := fmt.Sprintf("duplicates_%s.csv", time.Now().Format("2006-01-02-150405"))
+	file, err := os.Create(fileName)
+	if err != nil {
+		fmt.Printf("could not create output file: %+v\n", err)
+		return
+	}
+	defer file.Close() // nolint:errcheck
+
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+
+	// nolint:errcheck
+	writer.Write([]string{"cluster", "id_a", "key_a", "id_b", "key_b", "score", "reasons"})
+
+	// suggestions are indexed by the ninox id, since the key is not unique if
+	// a record was entered twice
+	suggestions := make(map[int][]string)
+	scores := make(map[int]float64)
+
+	for i, c := range clusters {
+		for _, m := range c.Matches {
+
+			// nolint:errcheck
+			writer.Write([]string{
+				strconv.Itoa(i + 1),
+				strconv.Itoa(m.A.ID), m.A.Key,
+				strconv.Itoa(m.B.ID), m.B.Key,
+				fmt.Sprintf("%.2f", m.Score),
+				strings.Join(m.Reasons, ", "),
+			})
+
+			suggestions[m.A.ID] = append(suggestions[m.A.ID], fmt.Sprintf("%s (%.2f)", m.B.Key, m.Score))
+			suggestions[m.B.ID] = append(suggestions[m.B.ID], fmt.Sprintf("%s (%.2f)", m.A.Key, m.Score))
+
+			for _, id := range []int{m.A.ID, m.B.ID} {
+				if m.Score > scores[id] {
+					scores[id] = m.Score
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+	file.Close() // nolint:errcheck
+
+	updated, cleared := suggestionUpdates(records, suggestions, scores)
+
+	fmt.Printf("suggestions written to %s\n", fileName)
+	fmt.Printf("updates for %d records (%d suggestions cleared)\n", len(updated), cleared)
+
+	if len(updated) == 0 {
+		return
+	}
+
+	var action string
+	fmt.Printf("Perform operation [n]: ")
+	fmt.Scanln(&action)
+
+	if action == "y" || action == "yes" {
+		err := ninox.UpdateRecords(ninox.CoveBasicURL, updated)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+	}
+
+}
+
+// suggestionUpdates will return the updates of the records, whose suggestions
+// have changed. suggestions of records without matches are cleared, including
+// records marked as duplicates since the suggestions were written
+func suggestionUpdates(records []ninox.Record, suggestions map[int][]string,
+	scores map[int]float64) ([]*ninox.Record, int) {
+
+	updated := []*ninox.Record{}
+	cleared := 0
+
+	for _, r := range records {
+
+		suggestion := strings.Join(suggestions[r.ID], "; ")
+		if r.Field("duplicate_suggestion") == suggestion {
+			continue
+		}
+
+		update := ninox.Record{}
+		update.ID = r.ID
+		update.Fields = make(map[string]interface{})
+		update.Fields["duplicate_suggestion"] = suggestion
+		update.Fields["duplicate_score"] = int(scores[r.ID] * 100)
+
+		if suggestion == "" {
+			update.Fields["duplicate_score"] = nil
+			cleared++
+		}
+
+		updated = append(updated, &update)
+	}
+
+	return updated, cleared
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+func TestSuggestionUpdates(t *testing.T) {
+
+	records := []ninox.Record{
+		{ID: 1, Fields: map[string]interface{}{"duplicate_suggestion": "ICTRP::DRKS00021238 (0.90)"}},
+		{ID: 2, Fields: map[string]interface{}{"duplicate_suggestion": "old (0.80)"}},
+		{ID: 3, Fields: map[string]interface{}{}},
+		// marked as duplicate after the suggestion was written, i.e. no candidate
+		{ID: 4, Fields: map[string]interface{}{"is_duplicate": "true", "duplicate_suggestion": "old (0.80)"}},
+		{ID: 5, Fields: map[string]interface{}{"is_duplicate": "true"}},
+	}
+
+	suggestions := map[int][]string{
+		1: {"ICTRP::DRKS00021238 (0.90)"},
+		3: {"ICTRP::DRKS00021238 (0.90)"},
+	}
+	scores := map[int]float64{1: 0.9, 3: 0.9}
+
+	updated, cleared := suggestionUpdates(records, suggestions, scores)
+
+	expected := map[int]interface{}{2: nil, 3: 90, 4: nil}
+
+	if len(updated) != len(expected) || cleared != 2 {
+		t.Fatalf("suggestionUpdates returned %d updates (%d cleared), expected %d (2 cleared)",
+			len(updated), cleared, len(expected))
+	}
+	for _, u := range updated {
+		score, ok := expected[u.ID]
+		if !ok {
+			t.Errorf("unexpected update of record %d", u.ID)
+			continue
+		}
+		if u.Fields["duplicate_score"] != score {
+			t.Errorf("record %d: duplicate_score %v, expected %v", u.ID, u.Fields["duplicate_score"], score)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+// main will list all ids (source ids and registry ids) that are shared by
+// multiple records in covebasic and the exclusions. the records are listed in
+// the order of their precedence in the index (see ninox.Index), i.e. the first
+// record of an id is the one used by the pipeline
+func main() {
+
+	log.Println("fetching records")
+
+	_, index, err := ninox.FetchCoveBasic(os.Args[1:]...)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+
+	collisions := index.Collisions()
+
+	fileName := fmt.Sprintf("id-collisions_%s.csv", time.Now().Format("2006-01-02"))
+	file, err := os.Create(fileName)
+	if err != nil {
+		log.Fatalf("could not create %s: %+v", fileName, err)
+	}
+	defer file.Close() // nolint:errcheck
+
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+
+	// nolint:errcheck
+	writer.Write([]string{"id", "precedence", "table", "record_id", "key", "by_source_id"})
+
+	for _, c := range collisions {
+		for i, info := range c.Records {
+			// nolint:errcheck
+			writer.Write([]string{
+				c.ID, strconv.Itoa(i + 1), info.Table, strconv.Itoa(info.ID),
+				info.Record.Key(), strconv.FormatBool(info.BySourceID),
+			})
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Fatalf("could not write %s: %+v", fileName, err)
+	}
+
+	fmt.Printf("%d ids are shared by multiple records (see %s)\n", len(collisions), fileName)
+}
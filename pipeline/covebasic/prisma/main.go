@@ -0,0 +1,333 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+// Flow contains the numbers of the prisma flow diagram at a given date
+type Flow struct {
+	Date string `json:"date"`
+
+	// records identified in the screening table of each source
+	Identified      map[string]int `json:"identified"`
+	IdentifiedTotal int            `json:"identified_total"`
+
+	// records without decision in the screening tables
+	AwaitingScreening int `json:"awaiting_screening"`
+
+	// records screened, i.e. excluded in the screening tables by screening
+	// decision or transferred to covebasic
+	Screened            int            `json:"screened"`
+	ExcludedScreening   int            `json:"excluded_screening"`
+	ExcludedScreeningBy map[string]int `json:"excluded_screening_by"`
+
+	// records screened and not excluded at screening (i.e. transferred to
+	// covebasic), excluded by exclusion rule
+	Assessed              int            `json:"assessed"`
+	ExcludedEligibility   int            `json:"excluded_eligibility"`
+	ExcludedEligibilityBy map[string]int `json:"excluded_eligibility_by"`
+
+	// records included in covebasic per source
+	Included   int            `json:"included"`
+	IncludedBy map[string]int `json:"included_by"`
+}
+
+// main will compute the numbers of the prisma flow diagram from the screening
+// tables, covebasic and the exclusions and write them as json, dot and svg.
+// an optional date (yyyy-mm-dd) computes the numbers for records created until
+// this date (i.e. for previous snapshots of the living review)
+func main() {
+
+	date := time.Now().Format("2006-01-02")
+	if len(os.Args) > 1 {
+		_, err := time.Parse("2006-01-02", os.Args[1])
+		if err != nil {
+			log.Fatalf("invalid date, expected yyyy-mm-dd: %s", os.Args[1])
+		}
+		date = os.Args[1]
+	}
+
+	log.Println("fetching records")
+
+	included, err := ninox.FetchRecords(ninox.CoveBasicURL, "")
+	if err != nil {
+		log.Fatalf("could not fetch covebasic records: %+v", err)
+	}
+
+	excluded, err := ninox.FetchRecords(ninox.CoveBasicExlusionURL, "")
+	if err != nil {
+		log.Fatalf("could not fetch exclusion records: %+v", err)
+	}
+
+	screening := make(map[string][]ninox.Record)
+	for _, source := range ninox.Sources {
+		records, err := ninox.FetchRecords(source.URL, "")
+		if err != nil {
+			log.Fatalf("could not fetch screening records of %s: %+v", source.Name, err)
+		}
+		screening[source.Name] = records
+	}
+
+	flow := computeFlow(date, screening, included, excluded)
+
+	fileName := fmt.Sprintf("prisma_%s", date)
+
+	content, err := json.MarshalIndent(flow, "", "\t")
+	if err != nil {
+		log.Fatalf("could not marshal flow: %+v", err)
+	}
+
+	outputs := map[string][]byte{
+		fileName + ".json": content,
+		fileName + ".dot":  []byte(renderDot(flow)),
+		fileName + ".svg":  []byte(renderSvg(flow)),
+	}
+
+	for name, content := range outputs {
+		err = ioutil.WriteFile(name, content, 0644)
+		if err != nil {
+			log.Fatalf("could not write %s: %+v", name, err)
+		}
+	}
+
+	fmt.Printf("identified: %d, screened: %d, assessed: %d, included: %d (see %s.*)\n",
+		flow.IdentifiedTotal, flow.Screened, flow.Assessed, flow.Included, fileName)
+}
+
+// states of the records of covebasic and the exclusions at the date of the
+// flow (empty if the record was not transferred to covebasic yet)
+const (
+	stateIncluded = "included"
+	stateExcluded = "excluded"
+)
+
+// otherSources is used for records in covebasic without screening record
+const otherSources = "other sources"
+
+// screeningLabels contains readable names of the screening decisions. the
+// codes 1 and 3 were entered manually by the screeners before the decisions
+// were named
+var screeningLabels = map[string]string{
+	"1":                   "excluded by screener (code 1)",
+	"3":                   "excluded by screener (code 3)",
+	"automatic exclusion": "automatic exclusion",
+}
+
+// computeFlow will compute the prisma numbers for the state at the given date.
+// records moved between covebasic and the exclusions after the date are
+// counted in the table they were in at the date (see stateAt). the numbers
+// of each stage are derived from the previous stage, i.e. screened records
+// are either excluded at screening or assessed for eligibility
+func computeFlow(date string, screening map[string][]ninox.Record,
+	included []ninox.Record, excluded []ninox.Record) Flow {
+
+	flow := Flow{
+		Date:                  date,
+		Identified:            make(map[string]int),
+		ExcludedScreeningBy:   make(map[string]int),
+		ExcludedEligibilityBy: make(map[string]int),
+		IncludedBy:            make(map[string]int),
+	}
+
+	// records moved back to covebasic remain in the exclusions, the pair is
+	// counted once with the state of the covebasic record
+	included = mergeRestored(included, excluded)
+
+	inCoveBasic := make(map[string]bool)
+	for i := range included {
+		inCoveBasic[included[i].Key()] = true
+	}
+
+	// index all records transferred to covebasic, with their state at the date
+	index := ninox.NewIndex()
+	states := make(map[*ninox.Record]string)
+	for i := range excluded {
+		if inCoveBasic[excluded[i].Key()] {
+			continue
+		}
+		index.Add(&excluded[i], ninox.CoveBasicExlusionsTable)
+		states[&excluded[i]] = stateAt(&excluded[i], ninox.CoveBasicExlusionsTable, date)
+	}
+	for i := range included {
+		index.Add(&included[i], ninox.CoveBasicTable)
+		states[&included[i]] = stateAt(&included[i], ninox.CoveBasicTable, date)
+	}
+
+	// assess will count the record transferred to covebasic in its state
+	assess := func(r *ninox.Record, state string) {
+		if state == stateExcluded {
+			reason := r.Field("exclusion_rule")
+			if reason == "" {
+				reason = "unknown"
+			}
+			flow.ExcludedEligibility++
+			flow.ExcludedEligibilityBy[reason]++
+			return
+		}
+		flow.Included++
+		flow.IncludedBy[r.Field("source")]++
+	}
+
+	matched := make(map[*ninox.Record]bool)
+
+	for _, source := range ninox.Sources {
+		for i := range screening[source.Name] {
+
+			r := &screening[source.Name][i]
+			if !until(r.CreatedAt, date) {
+				continue
+			}
+
+			flow.Identified[source.Name]++
+			flow.IdentifiedTotal++
+
+			// records in covebasic or the exclusions at the date passed the
+			// screening
+			if info, ok := index.GetKey(source.Name, r.Field(source.IDField)); ok {
+				matched[info.Record] = true
+				if state := states[info.Record]; state != "" {
+					flow.Screened++
+					assess(info.Record, state)
+					continue
+				}
+			}
+
+			// records included in the screening are awaiting the transfer
+			decision := r.Field("cove_screening")
+			if decision == "" || decision == "include" {
+				flow.AwaitingScreening++
+				continue
+			}
+
+			label, ok := screeningLabels[decision]
+			if !ok {
+				label = decision
+			}
+
+			flow.Screened++
+			flow.ExcludedScreening++
+			flow.ExcludedScreeningBy[label]++
+		}
+	}
+
+	// records without screening record (i.e. added manually) are identified
+	// from other sources. exclusions after the date are skipped, since the
+	// date they were added to covebasic is not known
+	other := func(r *ninox.Record) {
+		if matched[r] || states[r] == "" {
+			return
+		}
+		flow.Identified[otherSources]++
+		flow.IdentifiedTotal++
+		flow.Screened++
+		assess(r, states[r])
+	}
+
+	for i := range excluded {
+		if states[&excluded[i]] == stateExcluded {
+			other(&excluded[i])
+		}
+	}
+	for i := range included {
+		other(&included[i])
+	}
+
+	flow.Assessed = flow.Screened - flow.ExcludedScreening
+
+	return flow
+}
+
+// mergeRestored will return a copy of the covebasic records, in which records
+// moved back from the exclusions contain the exclusion of their record in the
+// exclusions table and an inclusion date (the date the covebasic record was
+// created if the date was not recorded)
+func mergeRestored(included []ninox.Record, excluded []ninox.Record) []ninox.Record {
+
+	exclusions := make(map[string]*ninox.Record)
+	for i := range excluded {
+		exclusions[excluded[i].Key()] = &excluded[i]
+	}
+
+	merged := make([]ninox.Record, len(included))
+	for i, r := range included {
+
+		merged[i] = r
+
+		exclusion, ok := exclusions[r.Key()]
+		if !ok {
+			continue
+		}
+
+		fields := make(map[string]interface{})
+		for name, value := range r.Fields {
+			fields[name] = value
+		}
+		for _, name := range []string{"exclusion_rule", "exclusion_date"} {
+			if r.Field(name) == "" {
+				fields[name] = exclusion.Fields[name]
+			}
+		}
+		if r.Field("inclusion_date") == "" {
+			fields["inclusion_date"] = r.CreatedAt
+		}
+
+		merged[i].Fields = fields
+	}
+
+	return merged
+}
+
+// stateAt will return the table the record of covebasic or the exclusions was
+// in at the given date. records are moved to the exclusions with an
+// exclusion_date and back to covebasic with an inclusion_date
+func stateAt(r *ninox.Record, table string, date string) string {
+
+	excludedAt := r.Field("exclusion_date")
+	includedAt := r.Field("inclusion_date")
+
+	if table == ninox.CoveBasicExlusionsTable {
+		if excludedAt == "" {
+			excludedAt = r.CreatedAt
+		}
+		if until(excludedAt, date) {
+			return stateExcluded
+		}
+		// excluded after the date, i.e. still in covebasic at the date
+		return stateIncluded
+	}
+
+	// records moved back to covebasic after the date were excluded at the
+	// date, unless they were also excluded after the date
+	if includedAt != "" {
+		if until(includedAt, date) {
+			return stateIncluded
+		}
+		if excludedAt != "" && until(excludedAt, date) {
+			return stateExcluded
+		}
+		return stateIncluded
+	}
+
+	if !until(r.CreatedAt, date) {
+		return ""
+	}
+	return stateIncluded
+}
+
+// until will check if the given date (iso date or timestamp) is not after the
+// date of the flow. records without date are always counted
+func until(value string, date string) bool {
+
+	if len(value) < len("2006-01-02") {
+		return true
+	}
+
+	return value[:len("2006-01-02")] <= date
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"testing"
+
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+// testRecord will create a record created at the given date with the fields
+func testRecord(created string, fields map[string]interface{}) ninox.Record {
+	return ninox.Record{CreatedAt: created + "T10:00:00", Fields: fields}
+}
+
+func TestComputeFlow(t *testing.T) {
+
+	trial := func(id string) map[string]interface{} {
+		return map[string]interface{}{"source": "clinicaltrials.gov", "source_id": id}
+	}
+	with := func(fields map[string]interface{}, extra map[string]interface{}) map[string]interface{} {
+		for name, value := range extra {
+			fields[name] = value
+		}
+		return fields
+	}
+
+	screening := map[string][]ninox.Record{
+		"clinicaltrials.gov": {
+			testRecord("2020-04-01", map[string]interface{}{"nct_id": "NCT1", "cove_screening": "include"}),
+			testRecord("2020-04-01", map[string]interface{}{"nct_id": "NCT2", "cove_screening": "include"}),
+			testRecord("2020-04-01", map[string]interface{}{"nct_id": "NCT3", "cove_screening": "include"}),
+			testRecord("2020-04-01", map[string]interface{}{"nct_id": "NCT4", "cove_screening": "1"}),
+			testRecord("2020-04-01", map[string]interface{}{"nct_id": "NCT5", "cove_screening": "3"}),
+			testRecord("2020-04-01", map[string]interface{}{"nct_id": "NCT6", "cove_screening": ""}),
+			testRecord("2020-04-01", map[string]interface{}{"nct_id": "NCT7", "cove_screening": "include"}),
+			testRecord("2020-04-01", map[string]interface{}{"nct_id": "NCT8", "cove_screening": "include"}),
+			// identified after the date
+			testRecord("2020-06-01", map[string]interface{}{"nct_id": "NCT9", "cove_screening": "include"}),
+		},
+	}
+
+	included := []ninox.Record{
+		// included before the date
+		testRecord("2020-04-02", trial("NCT1")),
+		// moved back from the exclusions after the date
+		testRecord("2020-06-01", with(trial("NCT7"), map[string]interface{}{
+			"exclusion_rule": "observational", "exclusion_date": "2020-04-10", "inclusion_date": "2020-06-01"})),
+		// transferred after the date
+		testRecord("2020-06-01", trial("NCT8")),
+		testRecord("2020-06-01", trial("NCT9")),
+		// added manually
+		testRecord("2020-04-05", map[string]interface{}{"source": "publication", "source_id": "PMID1"}),
+	}
+
+	excluded := []ninox.Record{
+		// excluded before the date
+		testRecord("2020-04-10", with(trial("NCT2"), map[string]interface{}{
+			"exclusion_rule": "not randomized", "exclusion_date": "2020-04-10"})),
+		// excluded after the date, i.e. included at the date
+		testRecord("2020-06-01", with(trial("NCT3"), map[string]interface{}{
+			"exclusion_rule": "not randomized", "exclusion_date": "2020-06-01"})),
+		// moved back to covebasic after the date (the record remains in the
+		// exclusions)
+		testRecord("2020-04-10", with(trial("NCT7"), map[string]interface{}{
+			"exclusion_rule": "observational", "exclusion_date": "2020-04-10"})),
+	}
+
+	flow := computeFlow("2020-05-01", screening, included, excluded)
+
+	numbers := []struct {
+		name  string
+		value int
+		want  int
+	}{
+		{"identified", flow.IdentifiedTotal, 9},
+		{"identified ctgov", flow.Identified["clinicaltrials.gov"], 8},
+		{"identified other", flow.Identified[otherSources], 1},
+		{"awaiting", flow.AwaitingScreening, 2},
+		{"screened", flow.Screened, 7},
+		{"excluded screening", flow.ExcludedScreening, 2},
+		{"excluded code 1", flow.ExcludedScreeningBy["excluded by screener (code 1)"], 1},
+		{"excluded code 3", flow.ExcludedScreeningBy["excluded by screener (code 3)"], 1},
+		{"assessed", flow.Assessed, 5},
+		{"excluded eligibility", flow.ExcludedEligibility, 2},
+		{"excluded not randomized", flow.ExcludedEligibilityBy["not randomized"], 1},
+		{"excluded observational", flow.ExcludedEligibilityBy["observational"], 1},
+		{"included", flow.Included, 3},
+		{"included ctgov", flow.IncludedBy["clinicaltrials.gov"], 2},
+	}
+
+	for _, n := range numbers {
+		if n.value != n.want {
+			t.Errorf("%s: %d, expected %d", n.name, n.value, n.want)
+		}
+	}
+
+	if flow.Screened != flow.ExcludedScreening+flow.Assessed ||
+		flow.Assessed != flow.ExcludedEligibility+flow.Included ||
+		flow.IdentifiedTotal != flow.AwaitingScreening+flow.Screened {
+		t.Errorf("flow numbers do not add up: %+v", flow)
+	}
+}
+
+func TestComputeFlowRestored(t *testing.T) {
+
+	screening := map[string][]ninox.Record{
+		"clinicaltrials.gov": {
+			testRecord("2020-04-01", map[string]interface{}{"nct_id": "NCT1", "cove_screening": "include"}),
+		},
+	}
+
+	// moved to the exclusions on 2020-04-10 and back on 2020-06-01, the record in
+	// the exclusions is kept without inclusion date (as in records restored
+	// before the inclusion date was written to the exclusions)
+	excluded := []ninox.Record{
+		testRecord("2020-04-10", map[string]interface{}{"source": "clinicaltrials.gov", "source_id": "NCT1",
+			"exclusion_rule": "not-trial", "exclusion_date": "2020-04-10"}),
+	}
+	included := []ninox.Record{
+		testRecord("2020-06-01", map[string]interface{}{"source": "clinicaltrials.gov", "source_id": "NCT1"}),
+	}
+
+	tests := []struct {
+		date     string
+		excluded int
+		included int
+	}{
+		{"2020-05-01", 1, 0},
+		{"2020-07-01", 0, 1},
+	}
+
+	for _, test := range tests {
+
+		flow := computeFlow(test.date, screening, included, excluded)
+
+		if flow.IdentifiedTotal != 1 || flow.Identified[otherSources] != 0 {
+			t.Errorf("%s: identified %d (%d from other sources), expected 1", test.date,
+				flow.IdentifiedTotal, flow.Identified[otherSources])
+		}
+		if flow.ExcludedEligibility != test.excluded || flow.Included != test.included {
+			t.Errorf("%s: excluded %d and included %d, expected %d and %d", test.date,
+				flow.ExcludedEligibility, flow.Included, test.excluded, test.included)
+		}
+	}
+
+	if included[0].Field("inclusion_date") != "" {
+		t.Errorf("computeFlow changed the covebasic records")
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+)
+
+// box is a single box of the flow diagram with the lines of text
+type box struct {
+	id    string
+	lines []string
+}
+
+// stage is a step of the flow diagram with the main box and an optional box
+// on the side for the records leaving the flow
+type stage struct {
+	main box
+	side *box
+}
+
+// stages will return the boxes of the flow diagram from top to bottom
+func stages(flow Flow) []stage {
+
+	identified := []string{fmt.Sprintf("Records identified (n = %d)", flow.IdentifiedTotal)}
+	identified = append(identified, breakdown(flow.Identified)...)
+
+	excludedScreening := []string{fmt.Sprintf("Records excluded (n = %d)", flow.ExcludedScreening)}
+	excludedScreening = append(excludedScreening, breakdown(flow.ExcludedScreeningBy)...)
+
+	excludedEligibility := []string{fmt.Sprintf("Records excluded (n = %d)", flow.ExcludedEligibility)}
+	excludedEligibility = append(excludedEligibility, breakdown(flow.ExcludedEligibilityBy)...)
+
+	included := []string{fmt.Sprintf("Trials included (n = %d)", flow.Included)}
+	included = append(included, breakdown(flow.IncludedBy)...)
+
+	return []stage{
+		{
+			main: box{"identified", identified},
+			side: &box{"awaiting", []string{
+				fmt.Sprintf("Records awaiting screening (n = %d)", flow.AwaitingScreening)}},
+		},
+		{
+			main: box{"screened", []string{fmt.Sprintf("Records screened (n = %d)", flow.Screened)}},
+			side: &box{"excluded_screening", excludedScreening},
+		},
+		{
+			main: box{"assessed", []string{
+				fmt.Sprintf("Records assessed for eligibility (n = %d)", flow.Assessed)}},
+			side: &box{"excluded_eligibility", excludedEligibility},
+		},
+		{
+			main: box{"included", included},
+		},
+	}
+}
+
+// breakdown will format the counts as indented lines ordered by name
+func breakdown(counts map[string]int) []string {
+
+	names := []string{}
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, len(names))
+	for i, name := range names {
+		label := name
+		if label == "" {
+			label = "unknown"
+		}
+		lines[i] = fmt.Sprintf("  %s: %d", label, counts[name])
+	}
+	return lines
+}
+
+// renderDot will render the flow diagram in the graphviz dot language
+func renderDot(flow Flow) string {
+
+	var b strings.Builder
+
+	label := func(lines []string) string {
+		escaped := make([]string, len(lines))
+		for i, line := range lines {
+			escaped[i] = strings.Replace(line, `"`, `\"`, -1)
+		}
+		return strings.Join(escaped, `\l`) + `\l`
+	}
+
+	fmt.Fprintf(&b, "digraph prisma {\n")
+	fmt.Fprintf(&b, "\tlabel=\"COVID-evidence flow diagram (%s)\";\n", flow.Date)
+	fmt.Fprintf(&b, "\tlabelloc=t;\n")
+	fmt.Fprintf(&b, "\tnode [shape=box, fontname=\"Helvetica\", fontsize=10];\n")
+
+	list := stages(flow)
+	for i, s := range list {
+		fmt.Fprintf(&b, "\t%s [label=\"%s\"];\n", s.main.id, label(s.main.lines))
+		if s.side != nil {
+			fmt.Fprintf(&b, "\t%s [label=\"%s\"];\n", s.side.id, label(s.side.lines))
+			fmt.Fprintf(&b, "\t{ rank=same; %s; %s; }\n", s.main.id, s.side.id)
+			fmt.Fprintf(&b, "\t%s -> %s;\n", s.main.id, s.side.id)
+		}
+		if i > 0 {
+			fmt.Fprintf(&b, "\t%s -> %s;\n", list[i-1].main.id, s.main.id)
+		}
+	}
+
+	fmt.Fprintf(&b, "}\n")
+	return b.String()
+}
+
+// layout of the svg diagram
+const (
+	svgMargin     = 20
+	svgBoxWidth   = 320
+	svgGap        = 60
+	svgLineHeight = 16
+	svgPadding    = 10
+	svgTitle      = 30
+)
+
+// renderSvg will render the flow diagram as svg, the layout is computed
+// directly to not depend on graphviz being installed
+func renderSvg(flow Flow) string {
+
+	list := stages(flow)
+
+	height := func(b *box) int {
+		return len(b.lines)*svgLineHeight + 2*svgPadding
+	}
+
+	var body strings.Builder
+
+	mainX := svgMargin
+	sideX := svgMargin + svgBoxWidth + svgGap
+	y := svgMargin + svgTitle
+
+	drawBox := func(b *box, x int, y int, h int) {
+		fmt.Fprintf(&body, `<rect x="%d" y="%d" width="%d" height="%d" fill="white" stroke="black"/>`+"\n",
+			x, y, svgBoxWidth, h)
+		for i, line := range b.lines {
+			indent := len(line) - len(strings.TrimLeft(line, " "))
+			fmt.Fprintf(&body, `<text x="%d" y="%d">%s</text>`+"\n",
+				x+svgPadding+indent*6, y+svgPadding+(i+1)*svgLineHeight-4,
+				html.EscapeString(strings.TrimSpace(line)))
+		}
+	}
+
+	for i := range list {
+		s := list[i]
+
+		// main and side box share the height of the higher box
+		h := height(&s.main)
+		if s.side != nil && height(s.side) > h {
+			h = height(s.side)
+		}
+
+		drawBox(&s.main, mainX, y, h)
+		if s.side != nil {
+			drawBox(s.side, sideX, y, h)
+			fmt.Fprintf(&body, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black" marker-end="url(#arrow)"/>`+"\n",
+				mainX+svgBoxWidth, y+h/2, sideX, y+h/2)
+		}
+
+		y += h
+		if i < len(list)-1 {
+			fmt.Fprintf(&body, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black" marker-end="url(#arrow)"/>`+"\n",
+				mainX+svgBoxWidth/2, y, mainX+svgBoxWidth/2, y+svgGap)
+			y += svgGap
+		}
+	}
+
+	width := sideX + svgBoxWidth + svgMargin
+	y += svgMargin
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="Helvetica" font-size="12">`+"\n",
+		width, y)
+	fmt.Fprintf(&b, `<defs><marker id="arrow" markerWidth="10" markerHeight="10" refX="9" refY="5" orient="auto">`+
+		`<path d="M0,0 L10,5 L0,10 z"/></marker></defs>`+"\n")
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="14">COVID-evidence flow diagram (%s)</text>`+"\n",
+		svgMargin, svgMargin+14, html.EscapeString(flow.Date))
+	b.WriteString(body.String())
+	fmt.Fprintf(&b, "</svg>\n")
+
+	return b.String()
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"dkfbasel.ch/covid-evidence/helpers"
+	"dkfbasel.ch/covid-evidence/ninox"
+	"dkfbasel.ch/covid-evidence/review"
+)
+
+// main will flag all covebasic records that are past the date when results
+// are expected without results being available. the job is meant to be run
+// periodically (i.e. weekly)
+func main() {
+
+	log.Println("fetching records")
+
+	records, err := ninox.FetchRecords(ninox.CoveBasicURL, "")
+	if err != nil {
+		fmt.Printf("could not fetch covebasic records: %+v\n", err)
+		return
+	}
+
+	today := time.Now()
+
+	// initialize the report
+	fileName := fmt.Sprintf("results-overdue_%s.csv", today.Format("2006-01-02"))
+	file, err := os.Create(fileName)
+	if err != nil {
+		fmt.Printf("could not create report: %+v\n", err)
+		return
+	}
+	defer file.Close() // nolint:errcheck
+
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+
+	// nolint:errcheck
+	writer.Write([]string{"id", "source", "source_id", "status", "end_date",
+		"results_expected_date", "results_available"})
+
+	updated := []*ninox.Record{}
+	overdueCount := 0
+
+	for _, r := range records {
+
+		overdue := "no"
+		if isOverdue(&r, today) {
+			overdue = "yes"
+			overdueCount++
+
+			// nolint:errcheck
+			writer.Write([]string{
+				helpers.AsString(r.ID), r.Field("source"), r.Field("source_id"),
+				r.Field("status"), r.Field("end_date"),
+				r.Field("results_expected_date"), r.Field("results_available"),
+			})
+		}
+
+		// nothing to do if the flag is already set correctly or the review
+		// status does not allow changes
+		if r.Field("results_overdue") == overdue || !review.Automated(r.Field("review_status")) {
+			continue
+		}
+
+		update := ninox.Record{}
+		update.ID = r.ID
+		update.Fields = make(map[string]interface{})
+		update.Fields["results_overdue"] = overdue
+		updated = append(updated, &update)
+	}
+
+	writer.Flush()
+	file.Close() // nolint:errcheck
+
+	fmt.Printf("results overdue for %d records (see %s)\n", overdueCount, fileName)
+	fmt.Printf("updates for %d records\n", len(updated))
+
+	if len(updated) == 0 {
+		return
+	}
+
+	var action string
+	fmt.Printf("Perform operation [n]: ")
+	fmt.Scanln(&action)
+
+	if action == "y" || action == "yes" {
+		err := ninox.UpdateRecords(ninox.CoveBasicURL, updated)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+	}
+
+}
+
+// isOverdue will check if the results of the given record are overdue, i.e. the
+// results expected date (or 12 months after the end date) has passed without
+// results being available
+func isOverdue(r *ninox.Record, today time.Time) bool {
+
+	if r.Field("results_available") == "yes" {
+		return false
+	}
+
+	// withdrawn trials will never report results
+	if r.Field("status") == "withdrawn" {
+		return false
+	}
+
+	expected := r.Field("results_expected_date")
+	if expected == "" {
+		expected = helpers.AddMonths(r.Field("end_date"), 12)
+	}
+
+	expectedDate, ok := helpers.ParseIsoDate(expected)
+	if !ok {
+		return false
+	}
+
+	return expectedDate.Before(today)
+}
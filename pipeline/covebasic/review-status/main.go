@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"dkfbasel.ch/covid-evidence/helpers"
+	"dkfbasel.ch/covid-evidence/ninox"
+	"dkfbasel.ch/covid-evidence/review"
+)
+
+// unknown is used for records without recorded date of the review status
+const unknown = "unknown"
+
+func main() {
+
+	command := "report"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	var err error
+
+	switch command {
+	case "report":
+		err = report()
+	case "set":
+		if len(os.Args) < 4 {
+			log.Fatalln("usage: review-status set <status> <record-id>...")
+		}
+		err = set(os.Args[2], os.Args[3:])
+	default:
+		log.Fatalln("usage: review-status [report|set <status> <record-id>...]")
+	}
+
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+
+}
+
+// report will count the covebasic records per review status and the
+// transitions into each status per month. transitions are taken from the
+// review status history, records changed before the history was recorded are
+// only counted in the month they reached their current status. records with a
+// status outside of the workflow are listed
+func report() error {
+
+	log.Println("fetching records")
+
+	records, err := ninox.FetchRecords(ninox.CoveBasicURL, "")
+	if err != nil {
+		return fmt.Errorf("could not fetch covebasic records: %w", err)
+	}
+
+	c := count(records)
+	monthly, invalid := c.transitions, c.invalid
+
+	// unknown months are reported first, since they precede the recorded dates
+	months := []string{}
+	for month := range monthly {
+		months = append(months, month)
+	}
+	sort.Slice(months, func(i, j int) bool {
+		if months[i] == unknown || months[j] == unknown {
+			return months[i] == unknown && months[j] != unknown
+		}
+		return months[i] < months[j]
+	})
+
+	fileName := fmt.Sprintf("review-status_%s.csv", time.Now().Format("2006-01-02"))
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("could not create report: %w", err)
+	}
+	defer file.Close() // nolint:errcheck
+
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+
+	// nolint:errcheck
+	writer.Write([]string{"month", "review_status", "transitions"})
+
+	for _, month := range months {
+		for _, state := range review.States {
+			if monthly[month][state] == 0 {
+				continue
+			}
+
+			// nolint:errcheck
+			writer.Write([]string{month, string(state), strconv.Itoa(monthly[month][state])})
+		}
+	}
+
+	writer.Flush()
+	file.Close() // nolint:errcheck
+
+	fmt.Printf("records: %d (see %s)\n", len(records), fileName)
+	for _, state := range review.States {
+		fmt.Printf("%-26s %d\n", string(state)+":", c.current[state])
+	}
+
+	values := []string{}
+	for value := range invalid {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	for _, value := range values {
+		fmt.Printf("invalid review status %q: %v\n", value, invalid[value])
+	}
+
+	return nil
+}
+
+// counts contains the number of records per review status and the number of
+// transitions into each status per month
+type counts struct {
+	current     map[review.Status]int
+	transitions map[string]map[review.Status]int
+	invalid     map[string][]int
+}
+
+// count will count the current review status and the transitions of the given
+// records. the current status is added as transition at review_status_date if
+// it is not the last change of the history, i.e. for records changed before
+// the history was recorded or changed directly in ninox
+func count(records []ninox.Record) counts {
+
+	c := counts{
+		current:     make(map[review.Status]int),
+		transitions: make(map[string]map[review.Status]int),
+		invalid:     make(map[string][]int),
+	}
+
+	add := func(date string, state review.Status) {
+
+		// records without date were set before the dates were recorded
+		month := unknown
+		if len(date) >= len("2006-01") {
+			month = date[:len("2006-01")]
+		}
+
+		if c.transitions[month] == nil {
+			c.transitions[month] = make(map[review.Status]int)
+		}
+		c.transitions[month][state]++
+	}
+
+	for _, r := range records {
+
+		value := r.Field("review_status")
+		state, ok := review.Parse(value)
+		if !ok {
+			c.invalid[value] = append(c.invalid[value], r.ID)
+			continue
+		}
+		c.current[state]++
+
+		history := review.ParseHistory(r.Field("review_status_history"))
+		for _, change := range history {
+			add(change.Date, change.Status)
+		}
+
+		if len(history) == 0 || history[len(history)-1].Status != state {
+			add(r.Field("review_status_date"), state)
+		}
+	}
+
+	return c
+}
+
+// set will change the review status of the given covebasic records, only the
+// transitions defined in the workflow are allowed
+func set(status string, ids []string) error {
+
+	to, ok := review.Parse(status)
+	if !ok {
+		return fmt.Errorf("unknown review status: %q", status)
+	}
+
+	log.Println("fetching records")
+
+	records, err := ninox.FetchRecords(ninox.CoveBasicURL, "")
+	if err != nil {
+		return fmt.Errorf("could not fetch covebasic records: %w", err)
+	}
+
+	index := make(map[int]*ninox.Record)
+	for i, r := range records {
+		index[r.ID] = &records[i]
+	}
+
+	currentDate := time.Now().Format("2006-01-02")
+	operator := helpers.Operator()
+
+	updates := []*ninox.Record{}
+
+	for _, value := range ids {
+
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid record id: %s", value)
+		}
+
+		current, ok := index[id]
+		if !ok {
+			fmt.Printf("% 6d: record does not exist\n", id)
+			continue
+		}
+
+		from := current.Field("review_status")
+		_, err = review.Transition(from, to)
+		if err != nil {
+			fmt.Printf("% 6d: %v\n", id, err)
+			continue
+		}
+
+		if from == string(to) {
+			continue
+		}
+
+		fmt.Printf("% 6d: %s -> %s\n", id, from, to)
+
+		r := ninox.Record{}
+		r.ID = id
+		r.Fields = make(map[string]interface{})
+		r.Fields["review_status"] = string(to)
+		r.Fields["review_status_date"] = currentDate
+		r.Fields["review_status_operator"] = operator
+		r.Fields["review_status_history"] = appendHistory(current, to, currentDate, operator)
+		updates = append(updates, &r)
+	}
+
+	fmt.Printf("updates for %d records\n", len(updates))
+
+	if len(updates) == 0 {
+		return nil
+	}
+
+	var action string
+	fmt.Printf("Perform operation [n]: ")
+	fmt.Scanln(&action) // nolint:errcheck
+
+	if action != "y" && action != "yes" {
+		return nil
+	}
+
+	return ninox.UpdateRecords(ninox.CoveBasicURL, updates)
+}
+
+// appendHistory will append the change to the given state to the review status
+// history of the record. the previous status is added first if the history is
+// empty, i.e. for records changed before the history was recorded
+func appendHistory(r *ninox.Record, to review.Status, date string, operator string) string {
+
+	history := r.Field("review_status_history")
+
+	if len(review.ParseHistory(history)) == 0 {
+		if from, ok := review.Parse(r.Field("review_status")); ok {
+			history = review.AppendHistory("", review.Change{
+				Date:     r.Field("review_status_date"),
+				Status:   from,
+				Operator: r.Field("review_status_operator"),
+			})
+		}
+	}
+
+	return review.AppendHistory(history, review.Change{Date: date, Status: to, Operator: operator})
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+
+	"dkfbasel.ch/covid-evidence/ninox"
+	"dkfbasel.ch/covid-evidence/review"
+)
+
+func TestCount(t *testing.T) {
+
+	records := []ninox.Record{
+		// record changed before the history was recorded
+		{ID: 1, Fields: map[string]interface{}{
+			"review_status": "prefilled automatically",
+		}},
+		{ID: 2, Fields: map[string]interface{}{
+			"review_status":      "review completed",
+			"review_status_date": "2020-05-10",
+		}},
+		// record with full history
+		{ID: 3, Fields: map[string]interface{}{
+			"review_status":      "verified",
+			"review_status_date": "2020-07-02",
+			"review_status_history": "2020-05-20;in extraction;anna\n" +
+				"2020-06-01;extracted;anna\n2020-06-15;in extraction;ben\n" +
+				"2020-06-20;extracted;anna\n2020-07-02;verified;ben",
+		}},
+		// record changed directly in ninox after the last recorded change
+		{ID: 4, Fields: map[string]interface{}{
+			"review_status":         "in extraction",
+			"review_status_date":    "2020-07-10",
+			"review_status_history": "2020-06-01;in extraction;anna\n2020-06-20;extracted;anna",
+		}},
+		{ID: 5, Fields: map[string]interface{}{"review_status": "done"}},
+	}
+
+	c := count(records)
+
+	expected := map[string]map[review.Status]int{
+		unknown:   {review.Prefilled: 1},
+		"2020-05": {review.Verified: 1, review.InExtraction: 1},
+		"2020-06": {review.Extracted: 3, review.InExtraction: 2},
+		"2020-07": {review.Verified: 1, review.InExtraction: 1},
+	}
+
+	if len(c.transitions) != len(expected) {
+		t.Errorf("transitions in %d months, expected %d", len(c.transitions), len(expected))
+	}
+	for month, states := range expected {
+		for _, state := range review.States {
+			if c.transitions[month][state] != states[state] {
+				t.Errorf("%s: %d transitions to %q, expected %d", month, c.transitions[month][state], state, states[state])
+			}
+		}
+	}
+
+	if c.current[review.Verified] != 2 || c.current[review.InExtraction] != 1 || c.current[review.Prefilled] != 1 {
+		t.Errorf("current status counts %v, expected 2 verified, 1 in extraction, 1 prefilled", c.current)
+	}
+	if len(c.invalid["done"]) != 1 || c.invalid["done"][0] != 5 {
+		t.Errorf("invalid status %v, expected record 5 with \"done\"", c.invalid)
+	}
+}
+
+func TestAppendHistory(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		fields  map[string]interface{}
+		history string
+	}{
+		{"without history", map[string]interface{}{
+			"review_status":          "prefilled automatically",
+			"review_status_date":     "2020-05-18",
+			"review_status_operator": "import",
+		}, "2020-05-18;prefilled automatically;import\n2020-07-01;in extraction;anna"},
+		{"with history", map[string]interface{}{
+			"review_status":         "prefilled automatically",
+			"review_status_history": "2020-06-01;prefilled automatically;import",
+		}, "2020-06-01;prefilled automatically;import\n2020-07-01;in extraction;anna"},
+	}
+
+	for _, test := range tests {
+		r := &ninox.Record{Fields: test.fields}
+		if history := appendHistory(r, review.InExtraction, "2020-07-01", "anna"); history != test.history {
+			t.Errorf("%s: appendHistory = %q, expected %q", test.name, history, test.history)
+		}
+	}
+}
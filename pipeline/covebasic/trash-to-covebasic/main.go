@@ -3,10 +3,16 @@ package main
 import (
 	"fmt"
 	"log"
+	"time"
 
+	"dkfbasel.ch/covid-evidence/helpers"
 	"dkfbasel.ch/covid-evidence/ninox"
+	"dkfbasel.ch/covid-evidence/rules"
 )
 
+// main will move all records of the exclusions table that match an inclusion
+// rule back to covebasic. the records remain in the exclusions table with the
+// inclusion date, so that the exclusion and its reversal can be reported
 func main() {
 
 	log.Println("fetching records")
@@ -18,15 +24,55 @@ func main() {
 		return
 	}
 
+	// records moved back before the inclusion date was written to the
+	// exclusions are identified by their key
+	basicRecords, err := ninox.FetchRecords(ninox.CoveBasicURL, "")
+	if err != nil {
+		fmt.Printf("could not fetch covebasic records: %+v\n", err)
+		return
+	}
+
+	inCoveBasic := make(map[string]bool)
+	for _, record := range basicRecords {
+		inCoveBasic[record.Key()] = true
+	}
+
 	include := []*ninox.Record{}
+	restored := []*ninox.Record{}
+
+	currentDate := time.Now().Format("2006-01-02")
+	operator := helpers.Operator()
 
 	for i, record := range exclusionRecords {
 
-		if record.Field("is_covid") == "yes" && record.Field("is_trial") == "yes" &&
-			record.Field("is_duplicate") == "false" {
-			include = append(include, &exclusionRecords[i])
+		// records moved back before are already in covebasic
+		if rules.Restored(record.Field) || inCoveBasic[record.Key()] {
+			continue
+		}
+
+		// check the inclusion rules (see rules/config.go), records are only
+		// included if no exclusion rule applies
+		rule, ok := rules.Included(record.Field)
+		if !ok {
+			continue
 		}
 
+		// annotate the record with the rule that included it
+		exclusionRecords[i].Fields["inclusion_rule"] = rule.Name
+
+		// the reason of the exclusion is kept for reference, the date shows
+		// that the exclusion was reverted
+		exclusionRecords[i].Fields["inclusion_date"] = currentDate
+		exclusionRecords[i].Fields["inclusion_operator"] = operator
+
+		// the record in the exclusions table is marked as restored
+		restored = append(restored, &ninox.Record{ID: record.ID, Fields: map[string]interface{}{
+			"inclusion_rule":     rule.Name,
+			"inclusion_date":     currentDate,
+			"inclusion_operator": operator,
+		}})
+
+		include = append(include, &exclusionRecords[i])
 	}
 
 	for i := range include {
@@ -35,13 +81,26 @@ func main() {
 
 	log.Printf("Items to move to covebasic table: %d", len(include))
 
+	if len(include) == 0 {
+		return
+	}
+
 	var action string
 	fmt.Printf("Perform operation [n]: ")
 	fmt.Scanln(&action)
 
 	if action == "y" || action == "yes" {
-		// import the exlusion records into the exlusion table
-		ninox.UpdateRecords(ninox.CoveBasicURL, include)
+		// import the exlusion records into the covebasic table
+		err := ninox.UpdateRecords(ninox.CoveBasicURL, include)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+
+		// mark the records in the exclusion table as restored
+		err = ninox.UpdateRecords(ninox.CoveBasicExlusionURL, restored)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
 	}
 
 }
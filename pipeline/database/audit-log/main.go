@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dkfbasel.ch/covid-evidence/helpers"
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+// usage of the command, filters can be combined
+const usage = `usage: audit-log runs
+       audit-log [run=<run-id>] [table=<table>] [id=<ninox-id>] [trial=<source-id>]
+                 [field=<field>] [user=<user>] [since=<yyyy-mm-dd>]`
+
+// main will query the audit log of the ninox client, i.e. to find out who
+// changed a field of a trial and what the previous value was
+func main() {
+
+	entries, err := ninox.ReadAudit()
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "runs" {
+		printRuns(entries)
+		return
+	}
+
+	filters := make(map[string]string)
+	for _, arg := range os.Args[1:] {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			log.Fatalln(usage)
+		}
+		switch parts[0] {
+		case "run", "table", "id", "trial", "field", "user", "since":
+			filters[parts[0]] = parts[1]
+		default:
+			log.Fatalln(usage)
+		}
+	}
+
+	count := 0
+	for _, entry := range entries {
+
+		if !matches(entry, filters) {
+			continue
+		}
+
+		for _, change := range changes(entry, filters["field"]) {
+			fmt.Println(change)
+			count++
+		}
+	}
+
+	fmt.Printf("%d changes (see %s)\n", count, ninox.AuditLogPath())
+}
+
+// matches will check if the entry matches all given filters
+func matches(entry ninox.AuditEntry, filters map[string]string) bool {
+
+	if run, ok := filters["run"]; ok && entry.Run != run {
+		return false
+	}
+
+	if table, ok := filters["table"]; ok && !strings.EqualFold(entry.Table, table) {
+		return false
+	}
+
+	if id, ok := filters["id"]; ok && strconv.Itoa(entry.ID) != id {
+		return false
+	}
+
+	// the trial is matched by the source id in the key (source::source_id)
+	if trial, ok := filters["trial"]; ok {
+		key := strings.ToLower(entry.Key)
+		trial = strings.ToLower(trial)
+		if key != trial && !strings.HasSuffix(key, "::"+trial) {
+			return false
+		}
+	}
+
+	if field, ok := filters["field"]; ok {
+		_, before := entry.Before[field]
+		_, after := entry.After[field]
+		if !before && !after {
+			return false
+		}
+	}
+
+	if user, ok := filters["user"]; ok && !strings.EqualFold(entry.User, user) {
+		return false
+	}
+
+	if since, ok := filters["since"]; ok && entry.Time.Format("2006-01-02") < since {
+		return false
+	}
+
+	return true
+}
+
+// changes will format the changed fields of the entry (only the given field
+// if specified), one line per field
+func changes(entry ninox.AuditEntry, field string) []string {
+
+	prefix := fmt.Sprintf("%s  %s  %-12s %-7s %-18s %6d  %s",
+		entry.Time.Format("2006-01-02 15:04:05"), entry.Run, entry.User,
+		entry.Operation, entry.Table, entry.ID, entry.Key)
+
+	if entry.Error != "" {
+		prefix = fmt.Sprintf("%s  (failed: %s)", prefix, entry.Error)
+	}
+
+	// deletions are reported as single line
+	if entry.Operation == ninox.AuditDelete {
+		return []string{prefix}
+	}
+
+	fields := []string{}
+	for name := range entry.After {
+		if field == "" || name == field {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+
+	lines := []string{}
+	for _, name := range fields {
+		before := helpers.AsString(entry.Before[name])
+		after := helpers.AsString(entry.After[name])
+
+		// unchanged values (i.e. fields sent again) are skipped
+		if entry.Operation == ninox.AuditUpdate && before == after {
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("%s  %s: %q -> %q", prefix, name, before, after))
+	}
+
+	return lines
+}
+
+// printRuns will print a summary of all runs in the audit log
+func printRuns(entries []ninox.AuditEntry) {
+
+	type summary struct {
+		run     string
+		started string
+		user    string
+		command string
+		counts  map[string]int
+		failed  int
+	}
+
+	runs := []*summary{}
+	index := make(map[string]*summary)
+
+	for _, entry := range entries {
+		s, ok := index[entry.Run]
+		if !ok {
+			s = &summary{
+				run:     entry.Run,
+				started: entry.Time.Format("2006-01-02 15:04:05"),
+				user:    entry.User,
+				command: entry.Command,
+				counts:  make(map[string]int),
+			}
+			index[entry.Run] = s
+			runs = append(runs, s)
+		}
+		s.counts[entry.Operation]++
+		if entry.Error != "" {
+			s.failed++
+		}
+	}
+
+	for _, s := range runs {
+		fmt.Printf("%s  %s  %-12s created: %4d, updated: %4d, deleted: %4d, failed: %d  %s\n",
+			s.run, s.started, s.user, s.counts[ninox.AuditCreate], s.counts[ninox.AuditUpdate],
+			s.counts[ninox.AuditDelete], s.failed, s.command)
+	}
+}
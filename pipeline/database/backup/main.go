@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"dkfbasel.ch/covid-evidence/backup"
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+// main will write a backup of all ninox tables used by the pipeline into a new
+// directory (./backups/ninox_<timestamp> or the given directory)
+func main() {
+
+	dir := filepath.Join(backup.Dir, fmt.Sprintf("ninox_%s", time.Now().Format("2006-01-02-150405")))
+	if len(os.Args) > 1 {
+		dir = os.Args[1]
+	}
+
+	b, err := backup.New(dir)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+
+	for _, table := range ninox.Tables() {
+
+		log.Printf("fetching records of %s", table.Name)
+
+		records, err := ninox.FetchRecords(table.URL, "")
+		if err != nil {
+			log.Fatalf("could not fetch records of %s: %+v", table.Name, err)
+		}
+
+		err = b.Add(table, records)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+
+		fmt.Printf("%-24s %6d records\n", table.Name+":", len(records))
+	}
+
+	err = b.Close()
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+
+	fmt.Printf("backup written to %s\n", dir)
+}
@@ -0,0 +1,461 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"dkfbasel.ch/covid-evidence/backup"
+	"dkfbasel.ch/covid-evidence/extraction"
+	"dkfbasel.ch/covid-evidence/helpers"
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+// usage of the command, the tables can be restricted by their name. the ids
+// file of a previous restore of covebasic is required to restore the
+// extractions without covebasic
+const usage = `usage: restore diff <backup-dir> [table...]
+       restore apply <backup-dir> <database-url> [restore_<date>--ids.csv] [table...]`
+
+// batchSize is the number of records inserted with a single request
+const batchSize = 500
+
+// differences between the backup and the live state
+const (
+	diffMissing = "only in backup"
+	diffAdded   = "only in ninox"
+	diffChanged = "changed"
+)
+
+func main() {
+
+	if len(os.Args) < 3 {
+		log.Fatalln(usage)
+	}
+
+	b, err := backup.Open(os.Args[2])
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+
+	log.Printf("backup %s of %s", b.Dir, b.Created())
+
+	switch os.Args[1] {
+	case "diff":
+		err = diff(b, selectTables(b, os.Args[3:]))
+	case "apply":
+		if len(os.Args) < 4 {
+			log.Fatalln(usage)
+		}
+		idsFile, names := splitIDsFile(os.Args[4:])
+		err = apply(b, os.Args[3], selectTables(b, names), idsFile)
+	default:
+		log.Fatalln(usage)
+	}
+
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+
+}
+
+// selectTables will return the tables of the backup with the given names or
+// all tables if no names are given
+func selectTables(b *backup.Backup, names []string) []backup.Table {
+
+	if len(names) == 0 {
+		return b.Tables
+	}
+
+	tables := []backup.Table{}
+	for _, name := range names {
+		found := false
+		for _, table := range b.Tables {
+			if strings.EqualFold(table.Name, name) {
+				tables = append(tables, table)
+				found = true
+				break
+			}
+		}
+		if !found {
+			log.Fatalf("table not found in backup: %s", name)
+		}
+	}
+
+	return tables
+}
+
+// splitIDsFile will separate the ids file (with the extension .csv) from the
+// table names in the given arguments
+func splitIDsFile(args []string) (string, []string) {
+
+	idsFile := ""
+	names := []string{}
+
+	for _, arg := range args {
+		if strings.HasSuffix(strings.ToLower(arg), ".csv") {
+			idsFile = arg
+			continue
+		}
+		names = append(names, arg)
+	}
+
+	return idsFile, names
+}
+
+// diff will compare the records in the backup with the current records in
+// ninox by their id and write all differences into a csv file
+func diff(b *backup.Backup, tables []backup.Table) error {
+
+	fileName := fmt.Sprintf("restore-diff_%s.csv", time.Now().Format("2006-01-02-150405"))
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %w", fileName, err)
+	}
+	defer file.Close() // nolint:errcheck
+
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+
+	// nolint:errcheck
+	writer.Write([]string{"table", "id", "key", "difference", "fields"})
+
+	for _, table := range tables {
+
+		backupRecords, err := b.Records(table)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("fetching records of %s", table.Name)
+
+		liveRecords, err := ninox.FetchRecords(table.URL, "")
+		if err != nil {
+			return fmt.Errorf("could not fetch records of %s: %w", table.Name, err)
+		}
+
+		live := make(map[int]*ninox.Record)
+		for i := range liveRecords {
+			live[liveRecords[i].ID] = &liveRecords[i]
+		}
+
+		counts := make(map[string]int)
+		seen := make(map[int]bool)
+
+		for i := range backupRecords {
+			r := &backupRecords[i]
+			seen[r.ID] = true
+
+			current, ok := live[r.ID]
+			if !ok {
+				counts[diffMissing]++
+				// nolint:errcheck
+				writer.Write([]string{table.Name, strconv.Itoa(r.ID), r.Key(), diffMissing, ""})
+				continue
+			}
+
+			fields := changedFields(r, current)
+			if len(fields) == 0 {
+				continue
+			}
+
+			counts[diffChanged]++
+			// nolint:errcheck
+			writer.Write([]string{table.Name, strconv.Itoa(r.ID), r.Key(), diffChanged,
+				strings.Join(fields, ", ")})
+		}
+
+		for i := range liveRecords {
+			r := &liveRecords[i]
+			if seen[r.ID] {
+				continue
+			}
+			counts[diffAdded]++
+			// nolint:errcheck
+			writer.Write([]string{table.Name, strconv.Itoa(r.ID), r.Key(), diffAdded, ""})
+		}
+
+		fmt.Printf("%-24s backup: %6d, ninox: %6d, %s: %d, %s: %d, %s: %d\n",
+			table.Name+":", len(backupRecords), len(liveRecords),
+			diffMissing, counts[diffMissing], diffAdded, counts[diffAdded],
+			diffChanged, counts[diffChanged])
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("could not write %s: %w", fileName, err)
+	}
+
+	fmt.Printf("differences written to %s\n", fileName)
+
+	return nil
+}
+
+// changedFields will return the names of all fields with different values
+func changedFields(a *ninox.Record, b *ninox.Record) []string {
+
+	fields := make(map[string]bool)
+	for name := range a.Fields {
+		fields[name] = true
+	}
+	for name := range b.Fields {
+		fields[name] = true
+	}
+
+	changed := []string{}
+	for name := range fields {
+		if helpers.AsString(a.Fields[name]) != helpers.AsString(b.Fields[name]) {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+
+	return changed
+}
+
+// apply will recreate the tables of the backup in the database with the given
+// url (i.e. https://api.ninoxdb.de/v1/teams/<team>/databases/<database>). the
+// tables must exist with the same table ids and must be empty. ninox assigns
+// new ids to the records, references of the extractions to covebasic are
+// updated accordingly and all ids are written into a csv file. the ids of
+// covebasic are read from the given ids file if the extractions are restored
+// without covebasic.
+//
+// only the fields are restored: the metadata of the records (i.e. createdAt,
+// createdBy, modifiedAt and modifiedBy) is set by ninox to the restore
+func apply(b *backup.Backup, databaseURL string, tables []backup.Table, idsFile string) error {
+
+	databaseURL = strings.TrimRight(databaseURL, "/")
+
+	// covebasic is restored first, since the extractions refer to its records
+	sort.SliceStable(tables, func(i, j int) bool {
+		return tables[i].Name == ninox.CoveBasicTable && tables[j].Name != ninox.CoveBasicTable
+	})
+
+	// ids of the restored covebasic records to update the extractions
+	covebasicIDs, err := prepareCoveBasicIDs(b, tables, idsFile)
+	if err != nil {
+		return err
+	}
+
+	targets := make(map[string]string)
+	for _, table := range tables {
+
+		// use the same table id in the target database
+		index := strings.Index(table.URL, "/tables/")
+		if index < 0 {
+			return fmt.Errorf("could not determine table id of %s: %s", table.Name, table.URL)
+		}
+		target := databaseURL + table.URL[index:]
+
+		existing, err := ninox.FetchRecords(target, "")
+		if err != nil {
+			return fmt.Errorf("could not fetch records of %s in target database: %w", table.Name, err)
+		}
+		if len(existing) > 0 {
+			return fmt.Errorf("table %s in target database is not empty (%d records)", table.Name, len(existing))
+		}
+
+		targets[table.Name] = target
+		fmt.Printf("%-24s %6d records -> %s\n", table.Name+":", table.Records, target)
+	}
+
+	var action string
+	fmt.Printf("Perform operation [n]: ")
+	fmt.Scanln(&action) // nolint:errcheck
+
+	if action != "y" && action != "yes" {
+		return nil
+	}
+
+	fileName := fmt.Sprintf("restore_%s--ids.csv", time.Now().Format("2006-01-02-150405"))
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %w", fileName, err)
+	}
+	defer file.Close() // nolint:errcheck
+
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+
+	// nolint:errcheck
+	writer.Write([]string{"table", "backup_id", "restored_id"})
+
+	for _, table := range tables {
+
+		records, err := b.Records(table)
+		if err != nil {
+			return err
+		}
+
+		for start := 0; start < len(records); start += batchSize {
+
+			end := start + batchSize
+			if end > len(records) {
+				end = len(records)
+			}
+
+			inserts := make([]*ninox.Record, end-start)
+			for i := range inserts {
+				r := records[start+i]
+				inserts[i] = &ninox.Record{Fields: r.Fields}
+
+				// references to records that are not restored are cleared, since
+				// the backup id refers to another record in the target database
+				if table.Name == ninox.CoveBasicExtractionTable {
+					id, ok := covebasicIDs[r.Field(extraction.FieldCoveBasicID)]
+					if ok {
+						inserts[i].Fields[extraction.FieldCoveBasicID] = id
+					} else {
+						inserts[i].Fields[extraction.FieldCoveBasicID] = nil
+					}
+				}
+			}
+
+			created, err := ninox.InsertRecords(targets[table.Name], inserts)
+			if err != nil {
+				writer.Flush()
+				return fmt.Errorf("could not restore records %d to %d of %s: %w", start, end, table.Name, err)
+			}
+
+			for i := range created {
+				backupID := strconv.Itoa(records[start+i].ID)
+				restoredID := strconv.Itoa(created[i].ID)
+
+				if table.Name == ninox.CoveBasicTable {
+					covebasicIDs[backupID] = created[i].ID
+				}
+
+				// nolint:errcheck
+				writer.Write([]string{table.Name, backupID, restoredID})
+			}
+		}
+
+		log.Printf("restored %d records of %s", len(records), table.Name)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("could not write %s: %w", fileName, err)
+	}
+
+	fmt.Printf("ids of the restored records written to %s\n", fileName)
+
+	return nil
+}
+
+// prepareCoveBasicIDs will check that the references of the extractions can be
+// updated and return the covebasic ids of a previous restore. if covebasic is
+// restored with the extractions, the ids are added during the restore
+func prepareCoveBasicIDs(b *backup.Backup, tables []backup.Table, idsFile string) (map[string]int, error) {
+
+	ids := make(map[string]int)
+
+	var extractions *backup.Table
+	restoresCoveBasic := false
+	for i := range tables {
+		switch tables[i].Name {
+		case ninox.CoveBasicTable:
+			restoresCoveBasic = true
+		case ninox.CoveBasicExtractionTable:
+			extractions = &tables[i]
+		}
+	}
+
+	if extractions == nil {
+		return ids, nil
+	}
+
+	// backup ids of the covebasic records available after the restore
+	available := make(map[string]bool)
+
+	switch {
+	case restoresCoveBasic:
+		for _, table := range b.Tables {
+			if table.Name != ninox.CoveBasicTable {
+				continue
+			}
+			records, err := b.Records(table)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range records {
+				available[strconv.Itoa(r.ID)] = true
+			}
+		}
+
+	case idsFile != "":
+		var err error
+		ids, err = readCoveBasicIDs(idsFile)
+		if err != nil {
+			return nil, err
+		}
+		for id := range ids {
+			available[id] = true
+		}
+
+	default:
+		return nil, fmt.Errorf("the extractions refer to covebasic records, restore covebasic " +
+			"with the extractions or pass the ids file of the restore of covebasic")
+	}
+
+	records, err := b.Records(*extractions)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := 0
+	for _, r := range records {
+		if !available[r.Field(extraction.FieldCoveBasicID)] {
+			missing++
+		}
+	}
+	if missing > 0 {
+		fmt.Printf("%d extractions refer to covebasic records that are not restored, "+
+			"their reference is cleared\n", missing)
+	}
+
+	return ids, nil
+}
+
+// readCoveBasicIDs will read the backup and restored ids of covebasic from the
+// ids file written by a previous restore
+func readCoveBasicIDs(fileName string) (map[string]int, error) {
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("could not open %s: %w", fileName, err)
+	}
+	defer file.Close() // nolint:errcheck
+
+	reader := csv.NewReader(file)
+	reader.Comma = ';'
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", fileName, err)
+	}
+
+	ids := make(map[string]int)
+	for i, row := range rows {
+		if i == 0 || len(row) < 3 || row[0] != ninox.CoveBasicTable {
+			continue
+		}
+		id, err := strconv.Atoi(row[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid restored id in line %d of %s: %s", i+1, fileName, row[2])
+		}
+		ids[row[1]] = id
+	}
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no covebasic ids found in %s", fileName)
+	}
+
+	return ids, nil
+}
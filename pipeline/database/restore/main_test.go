@@ -0,0 +1,96 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dkfbasel.ch/covid-evidence/backup"
+	"dkfbasel.ch/covid-evidence/extraction"
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+func TestPrepareCoveBasicIDs(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "restore")
+	if err != nil {
+		t.Fatalf("could not create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir) // nolint:errcheck
+
+	b, err := backup.New(filepath.Join(dir, "backup"))
+	if err != nil {
+		t.Fatalf("could not create backup: %v", err)
+	}
+
+	err = b.Add(ninox.Table{Name: ninox.CoveBasicTable, URL: ninox.CoveBasicURL},
+		[]ninox.Record{{ID: 10}, {ID: 11}})
+	if err != nil {
+		t.Fatalf("could not add covebasic: %v", err)
+	}
+
+	err = b.Add(ninox.Table{Name: ninox.CoveBasicExtractionTable, URL: ninox.CoveBasicExtractionURL},
+		[]ninox.Record{
+			{ID: 1, Fields: map[string]interface{}{extraction.FieldCoveBasicID: 10}},
+			{ID: 2, Fields: map[string]interface{}{extraction.FieldCoveBasicID: 11}},
+		})
+	if err != nil {
+		t.Fatalf("could not add extractions: %v", err)
+	}
+
+	idsFile := filepath.Join(dir, "restore_2020-07-20-120000--ids.csv")
+	err = ioutil.WriteFile(idsFile, []byte("table;backup_id;restored_id\n"+
+		"covebasic;10;100\ncovebasic;11;101\nexclusions;10;200\n"), 0644)
+	if err != nil {
+		t.Fatalf("could not write ids file: %v", err)
+	}
+
+	covebasic := b.Tables[0]
+	extractions := b.Tables[1]
+
+	tests := []struct {
+		name    string
+		tables  []backup.Table
+		idsFile string
+		ids     map[string]int
+		fails   bool
+	}{
+		{"without extractions", []backup.Table{covebasic}, "", map[string]int{}, false},
+		{"with covebasic", []backup.Table{extractions, covebasic}, "", map[string]int{}, false},
+		{"with ids file", []backup.Table{extractions}, idsFile, map[string]int{"10": 100, "11": 101}, false},
+		{"without covebasic", []backup.Table{extractions}, "", nil, true},
+		{"missing ids file", []backup.Table{extractions}, filepath.Join(dir, "missing.csv"), nil, true},
+	}
+
+	for _, test := range tests {
+
+		ids, err := prepareCoveBasicIDs(b, test.tables, test.idsFile)
+		if (err != nil) != test.fails {
+			t.Errorf("%s: prepareCoveBasicIDs returned %v", test.name, err)
+			continue
+		}
+
+		if len(ids) != len(test.ids) {
+			t.Errorf("%s: prepareCoveBasicIDs returned %v, expected %v", test.name, ids, test.ids)
+			continue
+		}
+		for backupID, id := range test.ids {
+			if ids[backupID] != id {
+				t.Errorf("%s: restored id of %s is %d, expected %d", test.name, backupID, ids[backupID], id)
+			}
+		}
+	}
+}
+
+func TestSplitIDsFile(t *testing.T) {
+
+	idsFile, names := splitIDsFile([]string{"extractions", "restore_2020-07-20--ids.csv"})
+
+	if idsFile != "restore_2020-07-20--ids.csv" {
+		t.Errorf("splitIDsFile returned ids file %q", idsFile)
+	}
+	if len(names) != 1 || names[0] != "extractions" {
+		t.Errorf("splitIDsFile returned tables %v, expected [extractions]", names)
+	}
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"dkfbasel.ch/covid-evidence/helpers"
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+// target identifies a record touched by the run
+type target struct {
+	url string
+	id  int
+}
+
+// touched contains all writes of the run to a single record
+type touched struct {
+	target  target
+	table   string
+	key     string
+	created bool
+	deleted map[string]interface{}
+
+	// values of the fields before the first and after the last write
+	before map[string]interface{}
+	after  map[string]interface{}
+}
+
+// restored is a record deleted by the run that was inserted again
+type restored struct {
+	table     string
+	key       string
+	deletedID int
+	id        int
+}
+
+// conflict is a field that was changed since the run and is therefore not
+// restored
+type conflict struct {
+	table    string
+	id       int
+	key      string
+	field    string
+	expected string
+	current  string
+}
+
+// main will roll back all writes of the given run from the audit log: fields
+// are reset to the values before the run, records created by the run are
+// deleted and records deleted by the run are inserted again (with a new id, see
+// rollback_<run>--ids.csv). fields changed since the run are skipped and
+// reported as conflicts
+func main() {
+
+	if len(os.Args) < 2 {
+		log.Fatalln("usage: rollback <run-id> (see audit-log runs)")
+	}
+	run := os.Args[1]
+
+	entries, err := ninox.ReadAudit()
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+
+	records := collect(entries, run)
+	if len(records) == 0 {
+		log.Fatalf("no writes found for run %s", run)
+	}
+
+	// fetch the current state of all tables touched by the run
+	current := make(map[target]*ninox.Record)
+	fetched := make(map[string]bool)
+	for _, t := range records {
+		if fetched[t.target.url] {
+			continue
+		}
+		fetched[t.target.url] = true
+
+		log.Printf("fetching records of %s", t.table)
+		list, err := ninox.FetchRecords(t.target.url, "")
+		if err != nil {
+			log.Fatalf("could not fetch records of %s: %+v", t.table, err)
+		}
+		for i := range list {
+			current[target{t.target.url, list[i].ID}] = &list[i]
+		}
+	}
+
+	updates := make(map[string][]*ninox.Record)
+	inserts := make(map[string][]*touched)
+	deletes := make(map[string][]int)
+	conflicts := []conflict{}
+	urls := []string{}
+
+	addURL := func(url string) {
+		for _, u := range urls {
+			if u == url {
+				return
+			}
+		}
+		urls = append(urls, url)
+	}
+
+	for _, t := range records {
+
+		r, exists := current[t.target]
+
+		switch {
+		case t.created:
+			// records created by the run are deleted if they were not changed
+			if !exists {
+				continue
+			}
+			changed := changedFields(t, r)
+			if len(changed) > 0 {
+				conflicts = append(conflicts, changed...)
+				continue
+			}
+			fmt.Printf("delete  %-18s %6d  %s\n", t.table, t.target.id, t.key)
+			deletes[t.target.url] = append(deletes[t.target.url], t.target.id)
+			addURL(t.target.url)
+
+		case t.deleted != nil:
+			// records deleted by the run are inserted again
+			fmt.Printf("restore %-18s %6d  %s\n", t.table, t.target.id, t.key)
+			inserts[t.target.url] = append(inserts[t.target.url], t)
+			addURL(t.target.url)
+
+		default:
+			if !exists {
+				fmt.Printf("skip    %-18s %6d  %s (record does not exist anymore)\n",
+					t.table, t.target.id, t.key)
+				continue
+			}
+
+			changed := changedFields(t, r)
+			skip := make(map[string]bool)
+			for _, c := range changed {
+				skip[c.field] = true
+			}
+			conflicts = append(conflicts, changed...)
+
+			reset := ninox.Record{ID: t.target.id, Fields: make(map[string]interface{})}
+			for field, value := range t.before {
+				if skip[field] {
+					continue
+				}
+				reset.Fields[field] = value
+			}
+			if len(reset.Fields) == 0 {
+				continue
+			}
+
+			fmt.Printf("reset   %-18s %6d  %s (%d fields)\n", t.table, t.target.id, t.key, len(reset.Fields))
+			updates[t.target.url] = append(updates[t.target.url], &reset)
+			addURL(t.target.url)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		fileName := fmt.Sprintf("rollback_%s--conflicts.csv", run)
+		err = writeConflicts(fileName, conflicts)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		fmt.Printf("conflicts: %d fields were changed since the run (see %s)\n", len(conflicts), fileName)
+	}
+
+	count := 0
+	for _, url := range urls {
+		count += len(updates[url]) + len(inserts[url]) + len(deletes[url])
+	}
+	fmt.Printf("records to roll back: %d\n", count)
+
+	if count == 0 {
+		return
+	}
+
+	var action string
+	fmt.Printf("Perform operation [n]: ")
+	fmt.Scanln(&action) // nolint:errcheck
+
+	if action != "y" && action != "yes" {
+		return
+	}
+
+	// the ids of the restored records are written even if the rollback fails,
+	// since other records may refer to the deleted ids
+	restoredIDs := []restored{}
+	idsFile := fmt.Sprintf("rollback_%s--ids.csv", run)
+
+	for _, url := range urls {
+		if len(updates[url]) > 0 {
+			err := ninox.UpdateRecords(url, updates[url])
+			if err != nil {
+				log.Fatalf("%+v", err)
+			}
+		}
+		if len(inserts[url]) > 0 {
+			ids, err := restore(url, inserts[url])
+			restoredIDs = append(restoredIDs, ids...)
+			if err != nil {
+				writeErr := writeRestored(idsFile, restoredIDs)
+				if writeErr != nil {
+					log.Printf("%+v", writeErr)
+				}
+				log.Fatalf("%+v", err)
+			}
+		}
+		if len(deletes[url]) > 0 {
+			err := ninox.DeleteRecords(url, deletes[url])
+			if err != nil {
+				log.Fatalf("%+v", err)
+			}
+		}
+	}
+
+	if len(restoredIDs) > 0 {
+		err = writeRestored(idsFile, restoredIDs)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		fmt.Printf("restored %d records with new ids (see %s)\n", len(restoredIDs), idsFile)
+	}
+
+	log.Printf("rolled back run %s (as run %s)", run, ninox.RunID())
+}
+
+// restore will insert the records deleted by the run again and return the
+// new ids assigned by ninox
+func restore(url string, records []*touched) ([]restored, error) {
+
+	list := []*ninox.Record{}
+	for _, t := range records {
+		list = append(list, &ninox.Record{Fields: restoredFields(t)})
+	}
+
+	created, err := ninox.InsertRecords(url, list)
+
+	ids := []restored{}
+	for i, r := range created {
+		if i >= len(records) {
+			break
+		}
+		ids = append(ids, restored{
+			table:     records[i].table,
+			key:       records[i].key,
+			deletedID: records[i].target.id,
+			id:        r.ID,
+		})
+	}
+
+	return ids, err
+}
+
+// restoredFields will return the fields of a record deleted by the run. the
+// record is restored with the values before the run, i.e. fields updated by
+// the run before the deletion are reset to their previous values
+func restoredFields(t *touched) map[string]interface{} {
+
+	fields := make(map[string]interface{})
+	for field, value := range t.deleted {
+		fields[field] = value
+	}
+	for field, value := range t.before {
+		fields[field] = value
+	}
+
+	return fields
+}
+
+// collect will combine all successful writes of the run per record
+func collect(entries []ninox.AuditEntry, run string) []*touched {
+
+	list := []*touched{}
+	index := make(map[target]*touched)
+
+	for _, entry := range entries {
+
+		if entry.Run != run || entry.Error != "" {
+			continue
+		}
+
+		key := target{entry.URL, entry.ID}
+		t, ok := index[key]
+		if !ok {
+			t = &touched{
+				target: key,
+				table:  entry.Table,
+				key:    entry.Key,
+				before: make(map[string]interface{}),
+				after:  make(map[string]interface{}),
+			}
+			index[key] = t
+			list = append(list, t)
+		}
+
+		switch entry.Operation {
+		case ninox.AuditCreate:
+			t.created = true
+		case ninox.AuditDelete:
+			t.deleted = entry.Before
+			continue
+		}
+
+		for field, value := range entry.After {
+			// keep the value before the first write of the run
+			if _, ok := t.before[field]; !ok {
+				t.before[field] = entry.Before[field]
+			}
+			t.after[field] = value
+		}
+	}
+
+	// records are rolled back in order of the table and id
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].table != list[j].table {
+			return list[i].table < list[j].table
+		}
+		return list[i].target.id < list[j].target.id
+	})
+
+	return list
+}
+
+// changedFields will return all fields whose current value differs from the
+// value written by the run
+func changedFields(t *touched, r *ninox.Record) []conflict {
+
+	conflicts := []conflict{}
+
+	fields := []string{}
+	for field := range t.after {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		expected := helpers.AsString(t.after[field])
+		current := r.Field(field)
+		if expected == current {
+			continue
+		}
+		conflicts = append(conflicts, conflict{
+			table:    t.table,
+			id:       t.target.id,
+			key:      t.key,
+			field:    field,
+			expected: expected,
+			current:  current,
+		})
+	}
+
+	return conflicts
+}
+
+// writeRestored will write the deleted and new ids of the restored records into
+// a csv file
+func writeRestored(fileName string, ids []restored) error {
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %w", fileName, err)
+	}
+	defer file.Close() // nolint:errcheck
+
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+
+	// nolint:errcheck
+	writer.Write([]string{"table", "deleted_id", "restored_id", "key"})
+
+	for _, r := range ids {
+		// nolint:errcheck
+		writer.Write([]string{r.table, fmt.Sprintf("%d", r.deletedID), fmt.Sprintf("%d", r.id), r.key})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeConflicts will write the conflicts into a csv file
+func writeConflicts(fileName string, conflicts []conflict) error {
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %w", fileName, err)
+	}
+	defer file.Close() // nolint:errcheck
+
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+
+	// nolint:errcheck
+	writer.Write([]string{"table", "id", "key", "field", "value_of_run", "current_value"})
+
+	for _, c := range conflicts {
+		// nolint:errcheck
+		writer.Write([]string{c.table, fmt.Sprintf("%d", c.id), c.key, c.field, c.expected, c.current})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
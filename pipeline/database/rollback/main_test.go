@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+func TestRestoredFields(t *testing.T) {
+
+	url := "https://ninox/tables/A/records"
+
+	entries := []ninox.AuditEntry{
+		{Run: "run", Operation: ninox.AuditUpdate, URL: url, ID: 1,
+			Before: map[string]interface{}{"status": "ongoing"},
+			After:  map[string]interface{}{"status": "completed"}},
+		{Run: "run", Operation: ninox.AuditUpdate, URL: url, ID: 1,
+			Before: map[string]interface{}{"status": "completed"},
+			After:  map[string]interface{}{"status": "terminated"}},
+		{Run: "run", Operation: ninox.AuditDelete, URL: url, ID: 1,
+			Before: map[string]interface{}{"status": "terminated", "title": "Trial"}},
+		{Run: "other", Operation: ninox.AuditUpdate, URL: url, ID: 1,
+			Before: map[string]interface{}{"title": "Old"},
+			After:  map[string]interface{}{"title": "Trial"}},
+		{Run: "run", Operation: ninox.AuditDelete, URL: url, ID: 2,
+			Before: map[string]interface{}{"title": "Deleted"}},
+	}
+
+	records := collect(entries, "run")
+	if len(records) != 2 {
+		t.Fatalf("collect returned %d records, expected 2", len(records))
+	}
+
+	expected := []map[string]string{
+		// updated before the deletion, restored with the values before the run
+		{"status": "ongoing", "title": "Trial"},
+		// only deleted, restored with the values of the deletion
+		{"title": "Deleted"},
+	}
+
+	for i, r := range records {
+		fields := restoredFields(r)
+		if len(fields) != len(expected[i]) {
+			t.Errorf("record %d restored with %v, expected %v", r.target.id, fields, expected[i])
+			continue
+		}
+		for field, value := range expected[i] {
+			if fields[field] != value {
+				t.Errorf("record %d restored with %s %v, expected %q", r.target.id, field, fields[field], value)
+			}
+		}
+	}
+}
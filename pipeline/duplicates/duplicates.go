@@ -0,0 +1,253 @@
+package duplicates
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"dkfbasel.ch/covid-evidence/helpers"
+)
+
+// weights used to combine the similarities if no identifier is shared
+const (
+	weightTitle      = 0.6
+	weightSponsor    = 0.2
+	weightEnrollment = 0.2
+)
+
+// Candidate contains the information of a record used for the detection of
+// duplicates
+type Candidate struct {
+	Key        string   // key of the record (source::source_id)
+	ID         int      // id of the record in ninox
+	IDs        []string // all identifiers of the record (source and secondary ids)
+	Title      string
+	Sponsor    string
+	Enrollment int
+
+	titleWords   map[string]bool
+	sponsorWords map[string]bool
+}
+
+// Match contains the similarity of two candidates
+type Match struct {
+	A       *Candidate
+	B       *Candidate
+	Score   float64
+	Reasons []string
+}
+
+// Cluster contains a group of candidates that are likely duplicates
+type Cluster struct {
+	Members []*Candidate
+	Matches []Match
+	Score   float64 // highest score of all matches in the cluster
+}
+
+// Score will calculate the similarity of the two candidates between 0 and 1.
+// candidates sharing an identifier are always considered duplicates
+func Score(a, b *Candidate) (float64, []string) {
+
+	prepare(a)
+	prepare(b)
+
+	for _, idA := range a.IDs {
+		for _, idB := range b.IDs {
+			if idA != "" && helpers.Clean(idA) == helpers.Clean(idB) {
+				return 1, []string{fmt.Sprintf("shared id %s", idA)}
+			}
+		}
+	}
+
+	reasons := []string{}
+	score := 0.0
+	weights := 0.0
+
+	if len(a.titleWords) > 0 && len(b.titleWords) > 0 {
+		similarity := jaccard(a.titleWords, b.titleWords)
+		score += weightTitle * similarity
+		weights += weightTitle
+		reasons = append(reasons, fmt.Sprintf("title %.2f", similarity))
+	}
+
+	if len(a.sponsorWords) > 0 && len(b.sponsorWords) > 0 {
+		similarity := jaccard(a.sponsorWords, b.sponsorWords)
+		score += weightSponsor * similarity
+		weights += weightSponsor
+		reasons = append(reasons, fmt.Sprintf("sponsor %.2f", similarity))
+	}
+
+	if a.Enrollment > 0 && b.Enrollment > 0 {
+		max := math.Max(float64(a.Enrollment), float64(b.Enrollment))
+		similarity := 1 - math.Abs(float64(a.Enrollment-b.Enrollment))/max
+		score += weightEnrollment * similarity
+		weights += weightEnrollment
+		reasons = append(reasons, fmt.Sprintf("enrollment %.2f", similarity))
+	}
+
+	// the title is required to compare candidates without shared identifier
+	if len(a.titleWords) == 0 || len(b.titleWords) == 0 {
+		return 0, reasons
+	}
+
+	return score / weights, reasons
+}
+
+// Find will compare all candidates and return clusters of candidates with a
+// similarity score of at least the given threshold
+func Find(candidates []*Candidate, threshold float64) []Cluster {
+
+	for _, c := range candidates {
+		prepare(c)
+	}
+
+	// only compare candidates that share an identifier or a title word that
+	// is not too common, to avoid comparing all pairs of candidates
+	blocks := make(map[string][]int)
+	for i, c := range candidates {
+		for _, id := range c.IDs {
+			if id != "" {
+				blocks["id:"+helpers.Clean(id)] = append(blocks["id:"+helpers.Clean(id)], i)
+			}
+		}
+		for word := range c.titleWords {
+			blocks["word:"+word] = append(blocks["word:"+word], i)
+		}
+	}
+
+	maxBlockSize := len(candidates)/20 + 2
+
+	compared := make(map[[2]int]bool)
+	matches := []Match{}
+
+	for key, members := range blocks {
+
+		if strings.HasPrefix(key, "word:") && len(members) > maxBlockSize {
+			continue
+		}
+
+		for x := 0; x < len(members); x++ {
+			for y := x + 1; y < len(members); y++ {
+
+				pair := [2]int{members[x], members[y]}
+				if compared[pair] {
+					continue
+				}
+				compared[pair] = true
+
+				a := candidates[pair[0]]
+				b := candidates[pair[1]]
+
+				score, reasons := Score(a, b)
+				if score < threshold {
+					continue
+				}
+
+				matches = append(matches, Match{A: a, B: b, Score: score, Reasons: reasons})
+			}
+		}
+	}
+
+	return cluster(candidates, matches)
+}
+
+// cluster will group all matches into clusters of connected candidates
+func cluster(candidates []*Candidate, matches []Match) []Cluster {
+
+	// union find on the candidates
+	parent := make(map[*Candidate]*Candidate)
+	var find func(c *Candidate) *Candidate
+	find = func(c *Candidate) *Candidate {
+		p, ok := parent[c]
+		if !ok || p == c {
+			return c
+		}
+		root := find(p)
+		parent[c] = root
+		return root
+	}
+
+	for _, m := range matches {
+		rootA := find(m.A)
+		rootB := find(m.B)
+		if rootA != rootB {
+			parent[rootA] = rootB
+		}
+	}
+
+	clusters := make(map[*Candidate]*Cluster)
+	order := []*Candidate{}
+
+	for _, m := range matches {
+		root := find(m.A)
+		c, ok := clusters[root]
+		if !ok {
+			c = &Cluster{}
+			clusters[root] = c
+			order = append(order, root)
+		}
+		c.Matches = append(c.Matches, m)
+		if m.Score > c.Score {
+			c.Score = m.Score
+		}
+	}
+
+	for _, candidate := range candidates {
+		c, ok := clusters[find(candidate)]
+		if ok {
+			c.Members = append(c.Members, candidate)
+		}
+	}
+
+	result := make([]Cluster, len(order))
+	for i, root := range order {
+		result[i] = *clusters[root]
+	}
+
+	// show the most likely duplicates first
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+
+	return result
+}
+
+// prepare will compute the normalized words of the candidate once
+func prepare(c *Candidate) {
+	if c.titleWords == nil {
+		c.titleWords = words(c.Title)
+	}
+	if c.sponsorWords == nil {
+		c.sponsorWords = words(c.Sponsor)
+	}
+}
+
+// words will return all normalized words of the given value with at least
+// three characters
+func words(value string) map[string]bool {
+	result := make(map[string]bool)
+	for _, word := range strings.Fields(value) {
+		word = helpers.Clean(word)
+		if len(word) < 3 {
+			continue
+		}
+		result[word] = true
+	}
+	return result
+}
+
+// jaccard will return the jaccard similarity of the two sets of words
+func jaccard(a, b map[string]bool) float64 {
+	intersection := 0
+	for word := range a {
+		if b[word] {
+			intersection++
+		}
+	}
+	union := len(a) + len(b) - intersection
+	if union == 0 {
+		return 0
+	}
+	return float64(intersection) / float64(union)
+}
@@ -0,0 +1,84 @@
+package duplicates
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+
+	title := "Hydroxychloroquine for the treatment of hospitalized patients with COVID-19"
+
+	tests := []struct {
+		name  string
+		a     Candidate
+		b     Candidate
+		score float64
+	}{
+		{"shared id",
+			Candidate{IDs: []string{"NCT04315948"}, Title: "Trial A"},
+			Candidate{IDs: []string{"EUCTR2020-000936-23", "nct04315948"}, Title: "Trial B"},
+			1},
+		{"empty ids are not shared",
+			Candidate{IDs: []string{""}},
+			Candidate{IDs: []string{""}},
+			0},
+		{"same title, sponsor and enrollment",
+			Candidate{Title: title, Sponsor: "University Hospital Basel", Enrollment: 100},
+			Candidate{Title: title, Sponsor: "University Hospital Basel", Enrollment: 100},
+			1},
+		{"same title only",
+			Candidate{Title: title},
+			Candidate{Title: title, Sponsor: "University Hospital Basel", Enrollment: 100},
+			1},
+		{"same title, different enrollment",
+			Candidate{Title: title, Enrollment: 100},
+			Candidate{Title: title, Enrollment: 50},
+			(0.6 + 0.2*0.5) / 0.8},
+		{"different titles",
+			Candidate{Title: "Remdesivir in severe covid"},
+			Candidate{Title: "Tocilizumab in critical pneumonia"},
+			0},
+		{"missing title",
+			Candidate{Sponsor: "University Hospital Basel", Enrollment: 100},
+			Candidate{Sponsor: "University Hospital Basel", Enrollment: 100},
+			0},
+	}
+
+	for _, test := range tests {
+		a, b := test.a, test.b
+		score, _ := Score(&a, &b)
+		if math.Abs(score-test.score) > 1e-9 {
+			t.Errorf("%s: Score = %.3f, expected %.3f", test.name, score, test.score)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+
+	candidates := []*Candidate{
+		{Key: "a", ID: 1, IDs: []string{"NCT04315948"}, Title: "DisCoVeRy trial"},
+		{Key: "b", ID: 2, IDs: []string{"EUCTR2020-000936-23", "NCT04315948"}, Title: "Trial of treatments for COVID-19"},
+		{Key: "c", ID: 3, IDs: []string{"EUCTR2020-000936-23"}, Title: "Treatments for hospitalized adults"},
+		{Key: "d", ID: 4, IDs: []string{"NCT04280705"}, Title: "Adaptive COVID-19 Treatment Trial"},
+		{Key: "e", ID: 5, IDs: []string{"ChiCTR2000029308"}, Title: "Lopinavir ritonavir in adults"},
+	}
+
+	clusters := Find(candidates, 0.75)
+
+	if len(clusters) != 1 {
+		t.Fatalf("Find returned %d clusters, expected 1", len(clusters))
+	}
+
+	members := []int{}
+	for _, m := range clusters[0].Members {
+		members = append(members, m.ID)
+	}
+	if len(members) != 3 || members[0] != 1 || members[1] != 2 || members[2] != 3 {
+		t.Errorf("cluster contains %v, expected [1 2 3]", members)
+	}
+	if len(clusters[0].Matches) != 2 || clusters[0].Score != 1 {
+		t.Errorf("cluster contains %d matches with score %.2f, expected 2 matches with score 1",
+			len(clusters[0].Matches), clusters[0].Score)
+	}
+}
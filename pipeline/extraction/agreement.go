@@ -0,0 +1,190 @@
+package extraction
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+// Pair contains the two independent extractions of a covebasic record
+type Pair struct {
+	CoveBasicID int
+	First       *ninox.Record
+	Second      *ninox.Record
+}
+
+// Agreement contains the agreement of the extractions for a single field
+type Agreement struct {
+	Field  string
+	Pairs  int
+	Agreed int
+
+	// Kappa is Cohen's kappa, it is not defined if the expected agreement
+	// is perfect (i.e. both extractors only used a single value)
+	Kappa        float64
+	KappaDefined bool
+}
+
+// Percent will return the percent agreement of the field
+func (a Agreement) Percent() float64 {
+	if a.Pairs == 0 {
+		return 0
+	}
+	return 100 * float64(a.Agreed) / float64(a.Pairs)
+}
+
+// Disagreement is a field of a covebasic record with different values in the
+// two extractions
+type Disagreement struct {
+	Pair   Pair
+	Field  string
+	First  string
+	Second string
+}
+
+// Pairs will group the records of the extraction table by the covebasic record
+// and return all records with two extractions (ordered by covebasic id), the
+// ids of records with an incomplete extraction and the ids of records with
+// more than two extractions (which must be resolved in ninox)
+func Pairs(records []ninox.Record) (pairs []Pair, incomplete []int, multiple []int) {
+
+	byID := make(map[int][]*ninox.Record)
+	ids := []int{}
+
+	for i := range records {
+		id, err := strconv.Atoi(records[i].Field(FieldCoveBasicID))
+		if err != nil || id == 0 {
+			continue
+		}
+		if _, ok := byID[id]; !ok {
+			ids = append(ids, id)
+		}
+		byID[id] = append(byID[id], &records[i])
+	}
+	sort.Ints(ids)
+
+	pairs = []Pair{}
+	incomplete = []int{}
+	multiple = []int{}
+
+	for _, id := range ids {
+		list := byID[id]
+
+		// the extraction number defines the order of the extractions
+		sort.SliceStable(list, func(a, b int) bool {
+			return list[a].Field(FieldExtraction) < list[b].Field(FieldExtraction)
+		})
+
+		if len(list) > 2 {
+			multiple = append(multiple, id)
+			continue
+		}
+
+		if len(list) != 2 || !Completed(list[0]) || !Completed(list[1]) {
+			incomplete = append(incomplete, id)
+			continue
+		}
+
+		pairs = append(pairs, Pair{CoveBasicID: id, First: list[0], Second: list[1]})
+	}
+
+	return pairs, incomplete, multiple
+}
+
+// Completed will check if any of the compared fields was extracted
+func Completed(r *ninox.Record) bool {
+	for _, field := range Fields {
+		if normalize(r.Field(field)) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare will compute the agreement of the given field over all pairs and
+// return the disagreements. fields empty in both extractions are not compared
+func Compare(field string, pairs []Pair) (Agreement, []Disagreement) {
+
+	agreement := Agreement{Field: field}
+	disagreements := []Disagreement{}
+
+	// count the values used by each extractor for the expected agreement
+	first := make(map[string]int)
+	second := make(map[string]int)
+
+	for _, pair := range pairs {
+
+		a := normalize(pair.First.Field(field))
+		b := normalize(pair.Second.Field(field))
+
+		if a == "" && b == "" {
+			continue
+		}
+
+		agreement.Pairs++
+		first[a]++
+		second[b]++
+
+		if a == b {
+			agreement.Agreed++
+			continue
+		}
+
+		disagreements = append(disagreements, Disagreement{
+			Pair:   pair,
+			Field:  field,
+			First:  pair.First.Field(field),
+			Second: pair.Second.Field(field),
+		})
+	}
+
+	agreement.Kappa, agreement.KappaDefined = kappa(agreement.Agreed, agreement.Pairs, first, second)
+
+	return agreement, disagreements
+}
+
+// kappa will compute Cohen's kappa from the observed agreement and the values
+// used by both extractors
+func kappa(agreed int, pairs int, first map[string]int, second map[string]int) (float64, bool) {
+
+	if pairs == 0 {
+		return 0, false
+	}
+
+	n := float64(pairs)
+	observed := float64(agreed) / n
+
+	expected := 0.0
+	for value, count := range first {
+		expected += (float64(count) / n) * (float64(second[value]) / n)
+	}
+
+	if expected >= 1 {
+		return 0, false
+	}
+
+	return (observed - expected) / (1 - expected), true
+}
+
+// normalize will prepare the value for the comparison, i.e. differences in
+// case, whitespace and order of list entries are ignored. lists are separated
+// by semicolon (as written by the pipeline, i.e. "placebo; standard of care")
+func normalize(value string) string {
+
+	value = strings.ToLower(strings.TrimSpace(value))
+	if !strings.Contains(value, ";") {
+		return value
+	}
+
+	entries := []string{}
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, "; ")
+}
@@ -0,0 +1,135 @@
+package extraction
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+// extractionRecord will create an extraction of the given covebasic record
+func extractionRecord(covebasicID int, number int, fields map[string]interface{}) ninox.Record {
+	r := ninox.Record{Fields: map[string]interface{}{
+		FieldCoveBasicID: covebasicID,
+		FieldExtraction:  number,
+	}}
+	for name, value := range fields {
+		r.Fields[name] = value
+	}
+	return r
+}
+
+func TestKappa(t *testing.T) {
+
+	// worked example of 50 ratings: both yes 20, first yes and second no 5,
+	// first no and second yes 10, both no 15. the observed agreement is 0.7,
+	// the expected agreement 0.5 * 0.6 + 0.5 * 0.4 = 0.5 and kappa therefore
+	// (0.7 - 0.5) / (1 - 0.5) = 0.4
+	value, ok := kappa(35, 50, map[string]int{"yes": 25, "no": 25}, map[string]int{"yes": 30, "no": 20})
+	if !ok || math.Abs(value-0.4) > 1e-9 {
+		t.Errorf("kappa = %v, %v, expected 0.4", value, ok)
+	}
+
+	// kappa is not defined if both extractors only used a single value
+	_, ok = kappa(10, 10, map[string]int{"yes": 10}, map[string]int{"yes": 10})
+	if ok {
+		t.Errorf("kappa defined for perfect expected agreement")
+	}
+
+	_, ok = kappa(0, 0, map[string]int{}, map[string]int{})
+	if ok {
+		t.Errorf("kappa defined without pairs")
+	}
+}
+
+func TestCompare(t *testing.T) {
+
+	// the worked example of TestKappa as extraction pairs
+	records := []ninox.Record{}
+	add := func(count int, first string, second string) {
+		for i := 0; i < count; i++ {
+			id := len(records)/2 + 1
+			records = append(records,
+				extractionRecord(id, 1, map[string]interface{}{"randomized": first}),
+				extractionRecord(id, 2, map[string]interface{}{"randomized": second}))
+		}
+	}
+	add(20, "yes", "yes")
+	add(5, "yes", "no")
+	add(10, "no", "yes")
+	add(15, "No ", "no")
+
+	pairs, incomplete, multiple := Pairs(records)
+	if len(pairs) != 50 || len(incomplete) != 0 || len(multiple) != 0 {
+		t.Fatalf("Pairs returned %d pairs, %d incomplete, %d multiple", len(pairs), len(incomplete), len(multiple))
+	}
+
+	agreement, disagreements := Compare("randomized", pairs)
+	if agreement.Pairs != 50 || agreement.Agreed != 35 || len(disagreements) != 15 {
+		t.Errorf("Compare returned %d pairs, %d agreed, %d disagreements",
+			agreement.Pairs, agreement.Agreed, len(disagreements))
+	}
+	if !agreement.KappaDefined || math.Abs(agreement.Kappa-0.4) > 1e-9 {
+		t.Errorf("Compare returned kappa %v", agreement.Kappa)
+	}
+	if math.Abs(agreement.Percent()-70) > 1e-9 {
+		t.Errorf("Compare returned %.1f%% agreement", agreement.Percent())
+	}
+}
+
+func TestPairs(t *testing.T) {
+
+	extracted := map[string]interface{}{"status": "recruiting"}
+
+	records := []ninox.Record{
+		// two complete extractions, in reverse order
+		extractionRecord(3, 2, extracted),
+		extractionRecord(3, 1, extracted),
+		// a single extraction
+		extractionRecord(1, 1, extracted),
+		// second extraction not started
+		extractionRecord(2, 1, extracted),
+		extractionRecord(2, 2, nil),
+		// three extractions
+		extractionRecord(4, 1, extracted),
+		extractionRecord(4, 2, extracted),
+		extractionRecord(4, 3, extracted),
+		// not linked to covebasic
+		extractionRecord(0, 1, extracted),
+	}
+
+	pairs, incomplete, multiple := Pairs(records)
+
+	if len(pairs) != 1 || pairs[0].CoveBasicID != 3 ||
+		pairs[0].First.Field(FieldExtraction) != "1" || pairs[0].Second.Field(FieldExtraction) != "2" {
+		t.Errorf("Pairs returned unexpected pairs: %+v", pairs)
+	}
+	if !reflect.DeepEqual(incomplete, []int{1, 2}) {
+		t.Errorf("Pairs returned incomplete %v, expected [1 2]", incomplete)
+	}
+	if !reflect.DeepEqual(multiple, []int{4}) {
+		t.Errorf("Pairs returned multiple %v, expected [4]", multiple)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{" Yes ", "yes"},
+		{"placebo; standard of care", "placebo; standard of care"},
+		{"Standard of care;placebo", "placebo; standard of care"},
+		{"drug; ; biological;", "biological; drug"},
+		{"lopinavir, ritonavir", "lopinavir, ritonavir"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if got := normalize(test.value); got != test.want {
+			t.Errorf("normalize(%q) = %q, expected %q", test.value, got, test.want)
+		}
+	}
+}
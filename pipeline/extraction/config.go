@@ -0,0 +1,33 @@
+package extraction
+
+// Fields contains the covebasic fields that are extracted independently by
+// two extractors and compared for the agreement. only fields with a limited
+// set of values are compared, since free text rarely matches exactly
+var Fields = []string{
+	"is_covid",
+	"is_trial",
+	"is_observational",
+	"entry_type",
+	"status",
+	"randomized",
+	"blinding",
+	"control",
+	"control_type",
+	"n_arms",
+	"n_enrollment",
+	"population_age",
+	"population_gender",
+	"intervention_type",
+	"longitudinal_structure",
+	"start_date",
+	"end_date",
+	"results_available",
+	"ipd_sharing",
+}
+
+// fields of the extraction table linking the extraction to covebasic
+const (
+	FieldCoveBasicID = "covebasic_id"
+	FieldExtraction  = "extraction"
+	FieldExtractor   = "extractor"
+)
@@ -1,11 +1,3 @@
 module dkfbasel.ch/covid-evidence
 
 go 1.14
-
-require (
-	github.com/go-chi/chi v4.1.0+incompatible
-	github.com/go-chi/render v1.0.1
-	github.com/stretchr/testify v1.4.0 // indirect
-	github.com/tidwall/gjson v1.6.0
-	golang.org/x/net v0.0.0-20200324143707-d3edc9973b7e // indirect
-)
@@ -2,6 +2,8 @@ package helpers
 
 import (
 	"fmt"
+	"os"
+	"os/user"
 	"strconv"
 	"strings"
 	"time"
@@ -25,8 +27,10 @@ func AsString(value interface{}) string {
 	switch t := value.(type) {
 	case int, int32, int64:
 		return fmt.Sprintf("%d", t)
-	case float32, float64:
-		return fmt.Sprintf("%.0f", t)
+	case float32:
+		return strconv.FormatFloat(float64(t), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
 	case bool:
 		return fmt.Sprintf("%t", t)
 	default:
@@ -35,6 +39,24 @@ func AsString(value interface{}) string {
 
 }
 
+// Clean will convert the value to lowercase and strip out all characters that
+// are not alphanumeric
+func Clean(value string) string {
+
+	s := []byte(value)
+
+	j := 0
+	for _, b := range s {
+		if ('a' <= b && b <= 'z') ||
+			('A' <= b && b <= 'Z') ||
+			('0' <= b && b <= '9') {
+			s[j] = b
+			j++
+		}
+	}
+	return strings.ToLower(string(s[:j]))
+}
+
 // ToIsoDate will attempt to parse and convert the given value to an iso date and
 // return the original string if parsing failes
 func ToIsoDate(value string) (interface{}, bool) {
@@ -90,6 +112,65 @@ func ToIsoDate(value string) (interface{}, bool) {
 	return value, false
 }
 
+// ParseIsoDate will parse the given iso date (with day or month precision)
+// and return false if the value is not a valid iso date
+func ParseIsoDate(value string) (time.Time, bool) {
+
+	asTime, err := time.Parse("2006-01-02", value)
+	if err == nil {
+		return asTime, true
+	}
+
+	asTime, err = time.Parse("2006-01", value)
+	if err == nil {
+		return asTime, true
+	}
+
+	return time.Time{}, false
+}
+
+// AddMonths will add the given number of months to the iso date (with day or
+// month precision) and return an empty string if the date is not valid
+func AddMonths(value string, months int) string {
+
+	asTime, ok := ParseIsoDate(value)
+	if !ok {
+		return ""
+	}
+
+	asTime = asTime.AddDate(0, months, 0)
+
+	// keep the precision of the given date
+	if len(value) == len("2006-01") {
+		return asTime.Format("2006-01")
+	}
+	return asTime.Format("2006-01-02")
+}
+
+// ToResultsExpectedDate will return the date 12 months after the given
+// completion date (as written by the registries or as iso date), results must
+// be reported within 12 months of the completion. an empty value is returned if
+// the completion date can not be parsed
+func ToResultsExpectedDate(completion string) (interface{}, bool) {
+
+	completion = strings.TrimSpace(completion)
+	if _, ok := ParseIsoDate(completion); ok {
+		return AddMonths(completion, 12), true
+	}
+
+	date, ok := ToIsoDate(completion)
+	if !ok {
+		return "", true
+	}
+	return AddMonths(AsString(date), 12), true
+}
+
+// AsGenerated will keep the value as is and mark it as generated (i.e. for
+// values derived by the pipeline)
+func AsGenerated(value string) (interface{}, bool) {
+	return value, true
+}
+
 // toLowerCase will convert the value to a lowercase string
 func ToLowerCase(value string) (interface{}, bool) {
 	return strings.ToLower(value), false
@@ -100,3 +181,20 @@ func ToInt(value string) (interface{}, bool) {
 	asInt, _ := strconv.Atoi(value)
 	return asInt, false
 }
+
+// Operator will return the name of the person running the pipeline, which can
+// be set with the environment variable COVE_OPERATOR (defaults to the name of
+// the current user)
+func Operator() string {
+
+	operator := strings.TrimSpace(os.Getenv("COVE_OPERATOR"))
+	if operator != "" {
+		return operator
+	}
+
+	current, err := user.Current()
+	if err != nil || current.Username == "" {
+		return "unknown"
+	}
+	return current.Username
+}
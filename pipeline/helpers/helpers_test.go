@@ -0,0 +1,49 @@
+package helpers
+
+import "testing"
+
+func TestToResultsExpectedDate(t *testing.T) {
+
+	tests := []struct {
+		completion string
+		want       string
+	}{
+		{"May 30, 2020", "2021-05-30"},
+		{"December 2020", "2021-12"},
+		{"Dec 2020", "2021-12"},
+		{"2020-05-30", "2021-05-30"},
+		{" 2020-12 ", "2021-12"},
+		{"30.05.20", "2021-05-30"},
+		{"", ""},
+		{"unknown", ""},
+	}
+
+	for _, test := range tests {
+		got, generated := ToResultsExpectedDate(test.completion)
+		if got != test.want || !generated {
+			t.Errorf("ToResultsExpectedDate(%q) = %q, %v, expected %q", test.completion, got, generated, test.want)
+		}
+	}
+}
+
+func TestAsString(t *testing.T) {
+
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, ""},
+		{120, "120"},
+		{float64(120), "120"},
+		{0.5, "0.5"},
+		{float32(17.25), "17.25"},
+		{true, "true"},
+		{"text", "text"},
+	}
+
+	for _, test := range tests {
+		if got := AsString(test.value); got != test.want {
+			t.Errorf("AsString(%v) = %q, expected %q", test.value, got, test.want)
+		}
+	}
+}
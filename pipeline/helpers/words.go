@@ -0,0 +1,44 @@
+package helpers
+
+import "strings"
+
+// ContainsWord will check if the text contains the given term as separate word,
+// i.e. not as part of a longer word (the comparison is case sensitive)
+func ContainsWord(text string, term string) bool {
+	return len(IndexWords(text, term)) > 0
+}
+
+// IndexWords will return the positions of all occurrences of the given term
+// in the text, which are not part of a longer word
+func IndexWords(text string, term string) []int {
+
+	positions := []int{}
+	if term == "" {
+		return positions
+	}
+
+	for start := 0; start < len(text); {
+
+		i := strings.Index(text[start:], term)
+		if i == -1 {
+			break
+		}
+		i += start
+
+		end := i + len(term)
+		before := i == 0 || !isAlphaNum(text[i-1])
+		after := end == len(text) || !isAlphaNum(text[end])
+		if before && after {
+			positions = append(positions, i)
+		}
+
+		start = i + 1
+	}
+
+	return positions
+}
+
+// isAlphaNum will check if the given byte is an ascii letter or digit
+func isAlphaNum(b byte) bool {
+	return ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z') || ('0' <= b && b <= '9')
+}
@@ -0,0 +1,32 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIndexWords(t *testing.T) {
+
+	tests := []struct {
+		text string
+		term string
+		want []int
+	}{
+		{"hydroxychloroquine", "chloroquine", []int{}},
+		{"hydroxy chloroquine", "chloroquine", []int{8}},
+		{"soc", "soc", []int{0}},
+		{"soc2 and soc", "soc", []int{9}},
+		{"placebo/soc, soc.", "soc", []int{8, 13}},
+		{"lopinavir/r", "lopinavir/r", []int{0}},
+		{"text", "", []int{}},
+	}
+
+	for _, test := range tests {
+		if got := IndexWords(test.text, test.term); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("IndexWords(%q, %q) = %v, expected %v", test.text, test.term, got, test.want)
+		}
+		if got := ContainsWord(test.text, test.term); got != (len(test.want) > 0) {
+			t.Errorf("ContainsWord(%q, %q) = %v", test.text, test.term, got)
+		}
+	}
+}
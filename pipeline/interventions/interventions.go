@@ -0,0 +1,242 @@
+package interventions
+
+import (
+	"sort"
+	"strings"
+
+	"dkfbasel.ch/covid-evidence/arms"
+	"dkfbasel.ch/covid-evidence/helpers"
+)
+
+// Intervention contains the information on a single intervention of a trial
+type Intervention struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Key will return the key used to reference the intervention in the arms
+// (i.e. "Drug: Hydroxychloroquine")
+func (i Intervention) Key() string {
+	if i.Type == "" {
+		return i.Name
+	}
+	return i.Type + ": " + i.Name
+}
+
+// match is a term of the dictionary found in the name of an intervention
+type match struct {
+	name  string
+	start int
+	end   int
+}
+
+// Normalize will return the normalized names of all interventions found in
+// the given name (i.e. "Hydroxychloroquine plus standard of care") in the order
+// of their occurrence, or the trimmed name if no intervention of the dictionary
+// is found. drugs qualified by placebo (i.e. "Placebo for hydroxychloroquine" or
+// "Hydroxychloroquine matching placebo") are not returned
+func Normalize(name string) []string {
+
+	lower := strings.ToLower(strings.TrimSpace(name))
+
+	found := find(lower, func(entry string) bool { return true })
+	if len(found) == 0 {
+		return []string{strings.TrimSpace(name)}
+	}
+
+	names := []string{}
+	for _, m := range found {
+		if m.name != Placebo && qualifiedByPlacebo(lower, m, found) {
+			continue
+		}
+		names = appendUnique(names, m.name)
+	}
+	return names
+}
+
+// placeboQualifiers are the only words that may separate placebo from the drug
+// it imitates (i.e. "placebo for hydroxychloroquine")
+var placeboQualifiers = map[string]bool{
+	"for": true, "of": true, "to": true, "matching": true, "matched": true,
+}
+
+// qualifiedByPlacebo will check if the given match is the drug imitated by a
+// placebo, i.e. if it is only separated by qualifying words from placebo
+func qualifiedByPlacebo(text string, m match, found []match) bool {
+
+	for _, placebo := range found {
+
+		if placebo.name != Placebo {
+			continue
+		}
+
+		var between string
+		switch {
+		case placebo.end <= m.start:
+			between = text[placebo.end:m.start]
+		case m.end <= placebo.start:
+			between = text[m.end:placebo.start]
+		default:
+			continue
+		}
+
+		// only spaces, hyphens and qualifying words are allowed in between
+		// (i.e. not "hydroxychloroquine + placebo")
+		qualified := true
+		for _, word := range strings.FieldsFunc(between, func(r rune) bool {
+			return r == ' ' || r == '-'
+		}) {
+			if !placeboQualifiers[word] {
+				qualified = false
+				break
+			}
+		}
+		if qualified {
+			return true
+		}
+	}
+
+	return false
+}
+
+// find will return all terms of the dictionary entries accepted by the filter
+// that are contained as separate words in the text, ordered by their position.
+// terms overlapping with a term found before are skipped (i.e. chloroquine in
+// "hydroxy chloroquine")
+func find(text string, filter func(entry string) bool) []match {
+
+	found := []match{}
+
+	overlaps := func(start, end int) bool {
+		for _, m := range found {
+			if start < m.end && m.start < end {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, entry := range synonyms {
+		if !filter(entry.Name) {
+			continue
+		}
+
+		// the longest terms are checked first (i.e. hydroxychloroquine sulfate)
+		terms := append([]string{entry.Name}, entry.Synonyms...)
+		sort.SliceStable(terms, func(i, j int) bool {
+			return len(terms[i]) > len(terms[j])
+		})
+
+		for _, term := range terms {
+			for _, start := range helpers.IndexWords(text, term) {
+				end := start + len(term)
+				if overlaps(start, end) {
+					continue
+				}
+				found = append(found, match{name: entry.Name, start: start, end: end})
+			}
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].start < found[j].start
+	})
+
+	return found
+}
+
+// isControl will check if the normalized name is placebo or standard of care
+func isControl(name string) bool {
+	return name == Placebo || name == StandardOfCare
+}
+
+// IsControl will check if the given intervention is placebo or standard of
+// care, i.e. if no other intervention is given (a drug combined with standard
+// of care is not a control)
+func IsControl(name string) bool {
+	for _, normalized := range Normalize(name) {
+		if !isControl(normalized) {
+			return false
+		}
+	}
+	return true
+}
+
+// Split will split the given interventions into experimental interventions
+// and controls. interventions are considered controls if they are placebo or
+// standard of care, or if they are only used in control arms
+func Split(interventions []Intervention, armList []arms.Arm) (experimental, control []Intervention) {
+
+	experimental = []Intervention{}
+	control = []Intervention{}
+
+	for _, intervention := range interventions {
+
+		if IsControl(intervention.Name) {
+			control = append(control, intervention)
+			continue
+		}
+
+		usedInControl := false
+		usedInExperimental := false
+
+		for _, arm := range armList {
+			for _, key := range arm.Interventions {
+				if !strings.EqualFold(key, intervention.Key()) &&
+					!strings.EqualFold(key, intervention.Name) {
+					continue
+				}
+				if arm.IsControl() {
+					usedInControl = true
+				} else {
+					usedInExperimental = true
+				}
+			}
+		}
+
+		if usedInControl && !usedInExperimental {
+			control = append(control, intervention)
+			continue
+		}
+
+		experimental = append(experimental, intervention)
+	}
+
+	return experimental, control
+}
+
+// Names will return the unique normalized names of the given interventions
+// separated by semicolon
+func Names(interventions []Intervention) string {
+	names := []string{}
+	for _, intervention := range interventions {
+		for _, name := range Normalize(intervention.Name) {
+			names = appendUnique(names, name)
+		}
+	}
+	return strings.Join(names, "; ")
+}
+
+// Types will return the unique types of the given interventions in lowercase
+// separated by semicolon
+func Types(interventions []Intervention) string {
+	types := []string{}
+	for _, intervention := range interventions {
+		if intervention.Type == "" {
+			continue
+		}
+		types = appendUnique(types, strings.ToLower(intervention.Type))
+	}
+	return strings.Join(types, "; ")
+}
+
+// appendUnique will append the value to the list if it is not yet contained
+func appendUnique(list []string, value string) []string {
+	for _, item := range list {
+		if item == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
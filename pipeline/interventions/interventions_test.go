@@ -0,0 +1,134 @@
+package interventions
+
+import (
+	"reflect"
+	"testing"
+
+	"dkfbasel.ch/covid-evidence/arms"
+)
+
+func TestNormalize(t *testing.T) {
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"Hydroxychloroquine", []string{"hydroxychloroquine"}},
+		{" HCQ ", []string{"hydroxychloroquine"}},
+		{"Hydroxychloroquine Sulfate 200 MG Oral Tablet", []string{"hydroxychloroquine"}},
+		{"Hydroxy chloroquine", []string{"hydroxychloroquine"}},
+		{"Chloroquine phosphate", []string{"chloroquine"}},
+		{"Hydroxychloroquine and Azithromycin", []string{"hydroxychloroquine", "azithromycin"}},
+		{"Azithromycin + HCQ", []string{"azithromycin", "hydroxychloroquine"}},
+		{"Lopinavir and ritonavir", []string{"lopinavir/ritonavir"}},
+		{"Placebo for hydroxychloroquine", []string{"placebo"}},
+		{"Hydroxychloroquine matching placebo", []string{"placebo"}},
+		{"Standard of care plus placebo", []string{"standard of care", "placebo"}},
+		{"Usual care", []string{"standard of care"}},
+		{"Hydroxychloroquine plus standard of care", []string{"hydroxychloroquine", "standard of care"}},
+		{"HCQ + SOC", []string{"hydroxychloroquine", "standard of care"}},
+		{"Tocilizumab and usual care", []string{"tocilizumab", "standard of care"}},
+		{"Placebo of HCQ", []string{"placebo"}},
+		{"Matching placebo", []string{"placebo"}},
+		{"Hydroxychloroquine + placebo", []string{"hydroxychloroquine", "placebo"}},
+		{"Double-dummy azithromycin", []string{"azithromycin"}},
+		{"Soccer training", []string{"Soccer training"}},
+		{" Unknown drug ", []string{"Unknown drug"}},
+	}
+
+	for _, test := range tests {
+		if got := Normalize(test.name); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Normalize(%q) = %q, expected %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestIsControl(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		control bool
+	}{
+		{"Placebo", true},
+		{"Placebo for hydroxychloroquine", true},
+		{"Standard of care", true},
+		{"Matching placebo", true},
+		{"Standard of care plus placebo", true},
+		{"Hydroxychloroquine plus standard of care", false},
+		{"HCQ + SOC", false},
+		{"Tocilizumab and usual care", false},
+		{"Double-dummy azithromycin", false},
+		{"Hydroxychloroquine", false},
+		{"Hydroxychloroquine and Azithromycin", false},
+		{"Unknown drug", false},
+	}
+
+	for _, test := range tests {
+		if got := IsControl(test.name); got != test.control {
+			t.Errorf("IsControl(%q) = %v, expected %v", test.name, got, test.control)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		list         []Intervention
+		armList      []arms.Arm
+		experimental string
+		control      string
+	}{
+		{"placebo qualifying a drug",
+			[]Intervention{
+				{Type: "Drug", Name: "Hydroxychloroquine and Azithromycin"},
+				{Type: "Drug", Name: "Placebo for hydroxychloroquine"},
+				{Type: "Drug", Name: "Lopinavir/ritonavir"},
+			},
+			[]arms.Arm{
+				{Label: "HCQ", Type: "Experimental", Interventions: []string{
+					"Drug: Hydroxychloroquine and Azithromycin"}},
+				{Label: "Control", Type: "Active Comparator", Interventions: []string{
+					"Drug: Placebo for hydroxychloroquine", "Drug: Lopinavir/ritonavir"}},
+			},
+			"hydroxychloroquine; azithromycin",
+			"placebo; lopinavir/ritonavir"},
+		{"drug with standard of care",
+			[]Intervention{
+				{Type: "Drug", Name: "HCQ plus SOC"},
+				{Type: "Other", Name: "Standard of care"},
+			},
+			[]arms.Arm{
+				{Label: "HCQ", Type: "Experimental", Interventions: []string{"Drug: HCQ plus SOC"}},
+				{Label: "SOC", Type: "Other", Interventions: []string{"Other: Standard of care"}},
+			},
+			"hydroxychloroquine; standard of care",
+			"standard of care"},
+		{"double dummy",
+			[]Intervention{
+				{Type: "Drug", Name: "Double-dummy azithromycin"},
+				{Type: "Drug", Name: "Tocilizumab and usual care"},
+			},
+			[]arms.Arm{
+				{Label: "AZM", Type: "Experimental", Interventions: []string{"Drug: Double-dummy azithromycin"}},
+				{Label: "TCZ", Type: "Experimental", Interventions: []string{"Drug: Tocilizumab and usual care"}},
+			},
+			"azithromycin; tocilizumab; standard of care",
+			""},
+	}
+
+	for _, test := range tests {
+
+		experimental, control := Split(test.list, test.armList)
+
+		if got := Names(experimental); got != test.experimental {
+			t.Errorf("%s: experimental = %q, expected %q", test.name, got, test.experimental)
+		}
+		if got := Types(experimental); test.experimental != "" && got == "" {
+			t.Errorf("%s: experimental types are empty", test.name)
+		}
+		if got := Names(control); got != test.control {
+			t.Errorf("%s: control = %q, expected %q", test.name, got, test.control)
+		}
+	}
+}
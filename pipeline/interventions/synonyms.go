@@ -0,0 +1,60 @@
+package interventions
+
+// names used for the control interventions
+const (
+	Placebo        = "placebo"
+	StandardOfCare = "standard of care"
+)
+
+// synonyms contains the normalized names of interventions together with
+// the spellings and brand names used in the registries. note that longer
+// names must be listed before names they contain (i.e. hydroxychloroquine
+// before chloroquine) if they are not separated by word boundaries
+var synonyms = []struct {
+	Name     string
+	Synonyms []string
+}{
+	{"hydroxychloroquine", []string{"hcq", "hydroxychloroquine sulfate",
+		"hydroxychloroquine sulphate", "hydroxy chloroquine", "hydroxy-chloroquine",
+		"hydroxychloroquin", "hydrochloroquine", "plaquenil"}},
+	{"chloroquine", []string{"cq", "chloroquine phosphate", "chloroquine diphosphate",
+		"chloroquin", "resochin"}},
+	{"azithromycin", []string{"azithromycine", "zithromax", "azm"}},
+	{"lopinavir/ritonavir", []string{"lopinavir-ritonavir", "lopinavir ritonavir",
+		"lopinavir and ritonavir", "lopinavir/r", "lpv/r", "lpv/rtv", "kaletra", "aluvia"}},
+	{"remdesivir", []string{"gs-5734", "gs5734"}},
+	{"favipiravir", []string{"avigan", "t-705", "favilavir"}},
+	{"umifenovir", []string{"arbidol"}},
+	{"oseltamivir", []string{"tamiflu"}},
+	{"ribavirin", []string{"ribavirine"}},
+	{"tocilizumab", []string{"actemra", "roactemra"}},
+	{"sarilumab", []string{"kevzara"}},
+	{"anakinra", []string{"kineret"}},
+	{"baricitinib", []string{"olumiant"}},
+	{"ruxolitinib", []string{"jakafi", "jakavi"}},
+	{"interferon beta", []string{"interferon beta-1a", "interferon beta-1b",
+		"interferon-beta", "ifn-beta", "ifn beta", "ifn-b", "betaferon", "rebif"}},
+	{"interferon alpha", []string{"interferon alpha-2b", "interferon alfa",
+		"interferon-alpha", "ifn-alpha", "ifn alpha"}},
+	{"ivermectin", []string{"stromectol"}},
+	{"nitazoxanide", []string{"alinia"}},
+	{"colchicine", []string{"colchicin"}},
+	{"dexamethasone", []string{"dexamethason"}},
+	{"methylprednisolone", []string{"methylprednisolon", "solu-medrol"}},
+	{"famotidine", []string{"pepcid"}},
+	{"losartan", []string{"losartan potassium", "cozaar"}},
+	{"enoxaparin", []string{"lovenox", "clexane"}},
+	{"heparin", []string{"unfractionated heparin", "ufh"}},
+	{"convalescent plasma", []string{"convalescent serum", "covid-19 convalescent plasma",
+		"anti-sars-cov-2 convalescent plasma", "hyperimmune plasma", "immune plasma"}},
+	{"bcg vaccine", []string{"bcg", "bacillus calmette-guerin", "bacille calmette-guerin"}},
+	{"vitamin d", []string{"cholecalciferol", "vitamin d3", "ergocalciferol"}},
+	{"vitamin c", []string{"ascorbic acid", "ascorbate"}},
+	{"zinc", []string{"zinc sulfate", "zinc sulphate", "zinc gluconate"}},
+	{Placebo, []string{"placebos", "placebo oral tablet", "placebo comparator",
+		"matching placebo", "sugar pill"}},
+	{StandardOfCare, []string{"soc", "standard care", "standard treatment",
+		"standard therapy", "standard of care treatment", "usual care",
+		"best supportive care", "supportive care", "conventional treatment",
+		"conventional therapy", "routine care"}},
+}
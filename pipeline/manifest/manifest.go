@@ -0,0 +1,187 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+)
+
+// Version is the version of the pipeline, it can be set at build time with
+// -ldflags "-X dkfbasel.ch/covid-evidence/manifest.Version=<version>"
+var Version = ""
+
+// Manifest describes the outputs of a single step of the pipeline and how
+// they were produced. manifests are written next to the outputs and reference
+// the manifests of their inputs, so that every value can be traced back to
+// the export it originates from
+type Manifest struct {
+	Step        string    `json:"step"` // i.e. fetch, parse, compare
+	Source      string    `json:"source"`
+	Query       string    `json:"query,omitempty"`
+	Expression  string    `json:"expression,omitempty"`
+	APIVersion  string    `json:"api_version,omitempty"`
+	DataVersion string    `json:"data_version,omitempty"`
+	ToolVersion string    `json:"tool_version"`
+	Started     time.Time `json:"started"`
+	Completed   time.Time `json:"completed"`
+
+	// Counts contains the number of records (i.e. studies, actions)
+	Counts map[string]int `json:"counts,omitempty"`
+
+	Outputs []File `json:"outputs"`
+	Inputs  []File `json:"inputs,omitempty"` // manifests of the inputs
+}
+
+// File contains the checksum of a file
+type File struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// New will initialize a new manifest for the given step of the source
+func New(step string, source string) *Manifest {
+	return &Manifest{
+		Step:        step,
+		Source:      source,
+		ToolVersion: ToolVersion(),
+		Started:     time.Now(),
+		Counts:      make(map[string]int),
+	}
+}
+
+// Path will return the path of the manifest of the given step of an export
+// (i.e. ./exports/clinicaltrials_2020-06-12-061937--parse.manifest.json)
+func Path(export string, step string) string {
+	return fmt.Sprintf("%s--%s.manifest.json", export, step)
+}
+
+// Load will read the manifest from the given path
+func Load(path string) (*Manifest, error) {
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read manifest: %w", err)
+	}
+
+	var m Manifest
+	err = json.Unmarshal(content, &m)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse manifest: %w", err)
+	}
+
+	return &m, nil
+}
+
+// AddOutput will add the checksum of the given output file to the manifest
+func (m *Manifest) AddOutput(path string) error {
+	file, err := checksum(path)
+	if err != nil {
+		return err
+	}
+	m.Outputs = append(m.Outputs, file)
+	return nil
+}
+
+// AddInput will add a reference to the manifest of an input. missing
+// manifests (i.e. of exports created before manifests were introduced) are
+// ignored
+func (m *Manifest) AddInput(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	file, err := checksum(path)
+	if err != nil {
+		return err
+	}
+	m.Inputs = append(m.Inputs, file)
+	return nil
+}
+
+// Write will mark the manifest as completed and write it to the given path
+func (m *Manifest) Write(path string) error {
+
+	m.Completed = time.Now()
+
+	content, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return fmt.Errorf("could not marshal manifest: %w", err)
+	}
+
+	err = ioutil.WriteFile(path, content, 0644)
+	if err != nil {
+		return fmt.Errorf("could not write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// Verify will check the checksums of all outputs of the manifest
+func (m *Manifest) Verify() error {
+	for _, output := range m.Outputs {
+		file, err := checksum(output.Path)
+		if err != nil {
+			return err
+		}
+		if file.SHA256 != output.SHA256 {
+			return fmt.Errorf("checksum of %s does not match the manifest", output.Path)
+		}
+	}
+	return nil
+}
+
+// Reference will return a short reference to the manifest at the given path,
+// which is stored with the records in ninox (i.e. name.manifest.json@3f2a9c1b0d4e)
+func Reference(path string) (string, error) {
+	file, err := checksum(path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s@%s", filepath.Base(path), file.SHA256[:12]), nil
+}
+
+// ToolVersion will return the version of the pipeline. if no version was set
+// at build time, the module version of the build is used together with the
+// vcs revision (i.e. "(devel) 1a2b3c4d5e6f" for builds of the working tree)
+func ToolVersion() string {
+
+	if Version != "" {
+		return Version
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" {
+		return "unknown"
+	}
+
+	if rev := revision(info); rev != "" {
+		return fmt.Sprintf("%s %s", info.Main.Version, rev)
+	}
+
+	return info.Main.Version
+}
+
+// checksum will compute the sha256 hash of the given file
+func checksum(path string) (File, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return File{}, fmt.Errorf("could not open %s: %w", path, err)
+	}
+	defer file.Close() // nolint:errcheck
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return File{}, fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	return File{Path: path, SHA256: hex.EncodeToString(hash.Sum(nil)), Size: size}, nil
+}
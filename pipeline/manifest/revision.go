@@ -0,0 +1,33 @@
+//go:build go1.18
+// +build go1.18
+
+package manifest
+
+import "runtime/debug"
+
+// revision will return the vcs revision of the build (abbreviated to 12
+// characters, with the suffix -dirty if the working tree was modified) or an
+// empty string if the build contains no vcs information
+func revision(info *debug.BuildInfo) string {
+
+	var rev, modified string
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			rev = setting.Value
+		case "vcs.modified":
+			modified = setting.Value
+		}
+	}
+
+	if rev == "" {
+		return ""
+	}
+	if len(rev) > 12 {
+		rev = rev[:12]
+	}
+	if modified == "true" {
+		rev += "-dirty"
+	}
+	return rev
+}
@@ -0,0 +1,12 @@
+//go:build !go1.18
+// +build !go1.18
+
+package manifest
+
+import "runtime/debug"
+
+// revision will return an empty string, the vcs information of the build is
+// only available with go 1.18 or newer
+func revision(info *debug.BuildInfo) string {
+	return ""
+}
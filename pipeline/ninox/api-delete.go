@@ -2,52 +2,79 @@ package ninox
 
 import (
 	"fmt"
-	"io/ioutil"
 	"log"
 	"net/http"
-	"time"
 )
 
-// DeleteRecords will delete the given records from the given database
-func DeleteRecords(url string, ids []int) {
+// DeleteRecords will delete the given records from the given database. the
+// deletion stops at the first record that could not be deleted
+func DeleteRecords(url string, ids []int) error {
 
-	// define transport properties
-	tr := &http.Transport{
-		MaxIdleConns:       10,
-		IdleConnTimeout:    30 * time.Second,
-		DisableCompression: true,
+	client := newClient()
+
+	for _, id := range ids {
+
+		content, err := deleteRecord(client, url, id)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("%s", content)
 	}
 
-	// initialize a new http client
-	client := &http.Client{Transport: tr}
+	return nil
+}
 
-	for _, id := range ids {
+// deleteRecord will delete a single record, each record must be deleted
+// individually
+func deleteRecord(client *http.Client, url string, id int) ([]byte, error) {
 
-		// each record must be deleted individually
-		deleteURL := fmt.Sprintf("%s/%d", url, id)
+	deleteURL := fmt.Sprintf("%s/%d", url, id)
 
-		log.Printf("DELETE: %s", deleteURL)
+	// the deleted record is recorded in the audit log
+	current, err := fetchRecord(client, url, id)
+	if err != nil {
+		return nil, err
+	}
 
-		// define a new request with corresponding authentication header
-		req, err := http.NewRequest("DELETE", deleteURL, nil)
-		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", ninoxAPIKey))
-		req.Header.Add("Content-Type", "application/json; charset=utf-8")
-		if err != nil {
-			log.Fatalf("could not create post request: %+v", err)
-		}
+	err = checkAudit()
+	if err != nil {
+		return nil, err
+	}
 
-		resp, err := client.Do(req)
-		if err != nil {
-			log.Fatalf("could not perform post request: %+v", err)
-		}
-		defer resp.Body.Close() // nolint:errcheck
+	log.Printf("DELETE: %s", deleteURL)
 
-		content, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			log.Fatalf("could not read response: %+v", err)
-		}
+	// define a new request with corresponding authentication header
+	req, err := http.NewRequest("DELETE", deleteURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create delete request: %w", err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", apiKey()))
+	req.Header.Add("Content-Type", "application/json; charset=utf-8")
 
-		log.Printf("%s", content)
+	content, requestErr := performRequest(client, req)
+
+	entry := AuditEntry{
+		Operation: AuditDelete,
+		Table:     tableName(url),
+		URL:       url,
+		ID:        id,
+	}
+	if current != nil {
+		entry.Before = current.Fields
+		entry.Key = auditKey(url, current)
+	}
+	if requestErr != nil {
+		entry.Error = requestErr.Error()
+	}
+
+	err = writeAudit([]AuditEntry{entry})
+	if err != nil {
+		if requestErr != nil {
+			return content, fmt.Errorf("%v, %w", requestErr, err)
+		}
+		return content, err
 	}
 
+	return content, requestErr
 }
@@ -40,7 +40,7 @@ func FetchRecords(endpoint string, filters string) ([]Record, error) {
 
 	// define a new request with corresponding authentication header
 	req, err := http.NewRequest("GET", endpointURL.String(), nil)
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", ninoxAPIKey))
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", apiKey()))
 	if err != nil {
 		return nil, fmt.Errorf("could not create request: %w", err)
 	}
@@ -0,0 +1,113 @@
+package ninox
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// Transaction groups updates and deletions in multiple tables that must be
+// applied together. ninox does not support transactions, the operations are
+// therefore applied in order and compensated if an operation fails: updated
+// records are reset to their previous values and deleted records are inserted
+// again (with a new ninox id)
+type Transaction struct {
+	operations []operation
+}
+
+// operation is a single update or deletion of a transaction
+type operation struct {
+	url      string
+	delete   bool
+	record   *Record
+	previous *Record
+}
+
+// NewTransaction will initialize a new transaction
+func NewTransaction() *Transaction {
+	return &Transaction{}
+}
+
+// Update will add the update of the record to the transaction. the previous
+// record (as fetched from ninox) is used to reset the fields on failure
+func (t *Transaction) Update(url string, record *Record, previous *Record) {
+	t.operations = append(t.operations, operation{url: url, record: record, previous: previous})
+}
+
+// Delete will add the deletion of the record to the transaction. the record
+// must contain all fields to restore it on failure
+func (t *Transaction) Delete(url string, record *Record) {
+	t.operations = append(t.operations, operation{url: url, delete: true, previous: record})
+}
+
+// Len will return the number of operations in the transaction
+func (t *Transaction) Len() int {
+	return len(t.operations)
+}
+
+// Commit will apply all operations of the transaction in the order they were
+// added. if an operation fails, all applied operations are compensated in
+// reverse order and the error is returned
+func (t *Transaction) Commit() error {
+
+	client := newClient()
+
+	for i, op := range t.operations {
+
+		err := op.apply(client)
+		if err == nil {
+			continue
+		}
+
+		err = fmt.Errorf("could not apply operation %d of %d: %w", i+1, len(t.operations), err)
+
+		// compensate all operations applied before
+		for j := i - 1; j >= 0; j-- {
+			rollbackErr := t.operations[j].rollback(client)
+			if rollbackErr != nil {
+				return fmt.Errorf("%v, could not roll back operation %d: %w", err, j+1, rollbackErr)
+			}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// apply will perform the operation
+func (op operation) apply(client *http.Client) error {
+	if op.delete {
+		_, err := deleteRecord(client, op.url, op.previous.ID)
+		return err
+	}
+	_, err := postRecords(client, op.url, []*Record{op.record})
+	return err
+}
+
+// rollback will compensate the operation
+func (op operation) rollback(client *http.Client) error {
+
+	if op.delete {
+		// insert the deleted record again
+		restored := Record{Fields: op.previous.Fields}
+		_, err := postRecords(client, op.url, []*Record{&restored})
+		if err == nil {
+			log.Printf("restored record %d in %s (with new id)", op.previous.ID, op.url)
+		}
+		return err
+	}
+
+	// reset all updated fields to the previous values
+	reset := Record{ID: op.record.ID, Fields: make(map[string]interface{})}
+	for field := range op.record.Fields {
+		var value interface{}
+		if op.previous != nil {
+			value = op.previous.Fields[field]
+		}
+		reset.Fields[field] = value
+	}
+
+	_, err := postRecords(client, op.url, []*Record{&reset})
+	return err
+}
@@ -7,46 +7,187 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 )
 
-// UpdateRecords will update the given records in the ninox database
-func UpdateRecords(url string, records []*Record) {
+// UpdateRecords will update the given records in the ninox database. records
+// without id are inserted
+func UpdateRecords(url string, records []*Record) error {
 
-	// define transport properties
-	tr := &http.Transport{
-		MaxIdleConns:       10,
-		IdleConnTimeout:    30 * time.Second,
-		DisableCompression: true,
+	content, err := postRecords(newClient(), url, records)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("%s", content)
+
+	return nil
+}
+
+// InsertRecords will insert the given records and return the created records
+// (in the same order) with the ids assigned by ninox
+func InsertRecords(url string, records []*Record) ([]Record, error) {
+
+	content, err := postRecords(newClient(), url, records)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []Record
+	err = json.Unmarshal(content, &created)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse response: %w", err)
 	}
 
-	// initialie a new http client
-	client := &http.Client{Transport: tr}
+	if len(created) != len(records) {
+		return created, fmt.Errorf("inserted %d records, but got %d records in the response",
+			len(records), len(created))
+	}
+
+	return created, nil
+}
+
+// postRecords will insert or update the given records and return the response
+// of ninox (i.e. the records with their ids)
+func postRecords(client *http.Client, url string, records []*Record) ([]byte, error) {
+
+	// the values before the update are recorded in the audit log
+	current, err := fetchCurrent(client, url, records)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkAudit()
+	if err != nil {
+		return nil, err
+	}
 
 	payload, err := json.Marshal(records)
 	if err != nil {
-		log.Fatalf("could not encode records for import: %+v", err)
+		return nil, fmt.Errorf("could not encode records for import: %w", err)
 	}
 
 	// define a new request with corresponding authentication header
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payload))
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", ninoxAPIKey))
+	if err != nil {
+		return nil, fmt.Errorf("could not create post request: %w", err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", apiKey()))
 	req.Header.Add("Content-Type", "application/json; charset=utf-8")
+
+	content, requestErr := performRequest(client, req)
+
+	// the response contains the created and updated records with their ids
+	var created []Record
+	if requestErr == nil {
+		json.Unmarshal(content, &created) // nolint:errcheck
+	}
+
+	err = writeAudit(auditChanges(url, records, current, created, requestErr))
 	if err != nil {
-		log.Fatalf("could not create post request: %+v", err)
+		if requestErr != nil {
+			return content, fmt.Errorf("%v, %w", requestErr, err)
+		}
+		return content, err
 	}
 
+	return content, requestErr
+}
+
+// performRequest will perform the request and return the response content,
+// responses with an error status are returned as error
+func performRequest(client *http.Client, req *http.Request) ([]byte, error) {
+
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Fatalf("could not perform post request: %+v", err)
+		return nil, fmt.Errorf("could not perform %s request: %w", strings.ToLower(req.Method), err)
 	}
 	defer resp.Body.Close() // nolint:errcheck
 
 	content, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Fatalf("could not read response: %+v", err)
+		return nil, fmt.Errorf("could not read response: %w", err)
 	}
 
-	log.Printf("%s", content)
+	if resp.StatusCode >= 300 {
+		return content, fmt.Errorf("%s request failed with status %d: %s", req.Method, resp.StatusCode, content)
+	}
+
+	return content, nil
+}
+
+// fetchCurrent will fetch the current values of the records to be updated.
+// each record is fetched by its id, so that only the records changed are
+// requested (and not the complete table)
+func fetchCurrent(client *http.Client, url string, records []*Record) (map[int]*Record, error) {
+
+	current := make(map[int]*Record)
+
+	for _, r := range records {
+
+		if r.ID == 0 {
+			continue
+		}
+
+		previous, err := fetchRecord(client, url, r.ID)
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch current records: %w", err)
+		}
+		if previous != nil {
+			current[r.ID] = previous
+		}
+	}
+
+	return current, nil
+}
+
+// fetchRecord will fetch a single record, nil is returned if the record does
+// not exist
+func fetchRecord(client *http.Client, url string, id int) (*Record, error) {
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/%d", url, id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", apiKey()))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch record %d: %w", id, err)
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not read response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("could not fetch record %d, status %d: %s", id, resp.StatusCode, content)
+	}
+
+	var r Record
+	err = json.Unmarshal(content, &r)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse record %d: %w", id, err)
+	}
+
+	return &r, nil
+}
+
+// newClient will initialize a new http client for the ninox api
+func newClient() *http.Client {
+
+	// define transport properties
+	tr := &http.Transport{
+		MaxIdleConns:       10,
+		IdleConnTimeout:    30 * time.Second,
+		DisableCompression: true,
+	}
 
+	return &http.Client{Transport: tr}
 }
@@ -0,0 +1,127 @@
+package ninox
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testServer will start a ninox api mock for the records table, which returns
+// the given status for all posts. all requests are recorded in the order received
+func testServer(t *testing.T, postStatus int) (*httptest.Server, *[]string) {
+
+	var mutex sync.Mutex
+	requests := []string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		mutex.Lock()
+		requests = append(requests, fmt.Sprintf("%s %s", r.Method, r.URL.Path))
+		mutex.Unlock()
+
+		switch {
+		case r.Method == "GET" && r.URL.Path != "/records":
+			id := strings.TrimPrefix(r.URL.Path, "/records/")
+			fmt.Fprintf(w, `{"id": %s, "fields": {"title": "before %s"}}`, id, id) // nolint:errcheck
+
+		case r.Method == "POST":
+			w.WriteHeader(postStatus)
+			content, _ := ioutil.ReadAll(r.Body)
+			w.Write(content) // nolint:errcheck
+
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+
+	return server, &requests
+}
+
+// setupAudit will write the audit log of the test to a temporary directory
+func setupAudit(t *testing.T) func() {
+
+	dir, err := ioutil.TempDir("", "ninox")
+	if err != nil {
+		t.Fatalf("could not create temporary directory: %v", err)
+	}
+
+	os.Setenv("NINOX_AUDIT_LOG", filepath.Join(dir, "audit.jsonl")) // nolint:errcheck
+	os.Setenv("NINOX_API_KEY", "test")                              // nolint:errcheck
+
+	return func() {
+		os.Unsetenv("NINOX_AUDIT_LOG") // nolint:errcheck
+		os.RemoveAll(dir)              // nolint:errcheck
+	}
+}
+
+func TestUpdateRecordsFetchesById(t *testing.T) {
+
+	defer setupAudit(t)()
+
+	server, requests := testServer(t, http.StatusOK)
+	defer server.Close()
+
+	url := server.URL + "/records"
+
+	records := []*Record{}
+	for id := 1; id <= 25; id++ {
+		records = append(records, &Record{ID: id, Fields: map[string]interface{}{"title": "after"}})
+	}
+	records = append(records, &Record{Fields: map[string]interface{}{"title": "new"}})
+
+	err := UpdateRecords(url, records)
+	if err != nil {
+		t.Fatalf("UpdateRecords returned %v", err)
+	}
+
+	// only the updated records are fetched, never the complete table
+	for _, request := range *requests {
+		if request == "GET /records" {
+			t.Errorf("UpdateRecords fetched the complete table")
+		}
+	}
+	if len(*requests) != 26 {
+		t.Errorf("UpdateRecords performed %d requests, expected 26", len(*requests))
+	}
+
+	entries, err := ReadAudit()
+	if err != nil {
+		t.Fatalf("could not read audit log: %v", err)
+	}
+	if len(entries) != 26 {
+		t.Fatalf("audit log contains %d entries, expected 26", len(entries))
+	}
+	if before := entries[0].Before["title"]; before != "before 1" {
+		t.Errorf("audit entry contains %v before the update, expected %q", before, "before 1")
+	}
+}
+
+func TestUpdateRecordsReturnsError(t *testing.T) {
+
+	defer setupAudit(t)()
+
+	server, _ := testServer(t, http.StatusInternalServerError)
+	defer server.Close()
+
+	url := server.URL + "/records"
+
+	err := UpdateRecords(url, []*Record{{ID: 1, Fields: map[string]interface{}{"title": "after"}}})
+	if err == nil {
+		t.Fatalf("UpdateRecords returned no error for a failed request")
+	}
+
+	entries, err := ReadAudit()
+	if err != nil {
+		t.Fatalf("could not read audit log: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Error == "" {
+		t.Errorf("audit log contains %+v, expected the failed update", entries)
+	}
+}
@@ -0,0 +1,247 @@
+package ninox
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"dkfbasel.ch/covid-evidence/helpers"
+)
+
+// operations recorded in the audit log
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditEntry is a single write of the ninox client. before contains the values
+// of the changed fields before the write (all fields for deletions) and after
+// the values written (nil for deletions)
+type AuditEntry struct {
+	Run       string                 `json:"run"`
+	Time      time.Time              `json:"time"`
+	Command   string                 `json:"command"`
+	User      string                 `json:"user"`
+	Operation string                 `json:"operation"`
+	Table     string                 `json:"table"`
+	URL       string                 `json:"url"`
+	ID        int                    `json:"id"`
+	Key       string                 `json:"key,omitempty"`
+	Before    map[string]interface{} `json:"before,omitempty"`
+	After     map[string]interface{} `json:"after,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// AuditLogPath will return the path of the audit log, which can be set with
+// the environment variable NINOX_AUDIT_LOG (defaults to the home directory so
+// that all commands write to the same log)
+func AuditLogPath() string {
+
+	if path := os.Getenv("NINOX_AUDIT_LOG"); path != "" {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "ninox-audit.jsonl"
+	}
+	return filepath.Join(home, ".covid-evidence", "ninox-audit.jsonl")
+}
+
+// auditRun identifies all writes of the current process
+var auditRun = newRunID()
+
+// auditMutex serializes the writes to the audit log
+var auditMutex sync.Mutex
+
+// RunID will return the id of the current run in the audit log
+func RunID() string {
+	return auditRun
+}
+
+// newRunID will generate an id from the start time and a random suffix
+func newRunID() string {
+	suffix := make([]byte, 3)
+	_, err := rand.Read(suffix)
+	if err != nil {
+		return time.Now().Format("20060102-150405")
+	}
+	return fmt.Sprintf("%s-%s", time.Now().Format("20060102-150405"), hex.EncodeToString(suffix))
+}
+
+// writeAudit will append the entries to the audit log, the run, time, command
+// and user are set for all entries
+func writeAudit(entries []AuditEntry) error {
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+
+	file, err := openAudit()
+	if err != nil {
+		return err
+	}
+	defer file.Close() // nolint:errcheck
+
+	now := time.Now()
+	command := strings.Join(append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...), " ")
+	user := helpers.Operator()
+
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		entry.Run = auditRun
+		entry.Time = now
+		entry.Command = command
+		entry.User = user
+
+		err = encoder.Encode(entry)
+		if err != nil {
+			return fmt.Errorf("could not write audit log: %w", err)
+		}
+	}
+
+	return file.Close()
+}
+
+// checkAudit will check that the audit log can be written, writes to ninox
+// must not be performed without audit log
+func checkAudit() error {
+	file, err := openAudit()
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// openAudit will open the audit log for appending
+func openAudit() (*os.File, error) {
+
+	path := AuditLogPath()
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, fmt.Errorf("could not create directory of audit log: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("could not open audit log: %w", err)
+	}
+	return file, nil
+}
+
+// ReadAudit will read all entries of the audit log
+func ReadAudit() ([]AuditEntry, error) {
+
+	file, err := os.Open(AuditLogPath())
+	if err != nil {
+		return nil, fmt.Errorf("could not open audit log: %w", err)
+	}
+	defer file.Close() // nolint:errcheck
+
+	entries := []AuditEntry{}
+	decoder := json.NewDecoder(file)
+
+	for decoder.More() {
+		var entry AuditEntry
+		err = decoder.Decode(&entry)
+		if err != nil {
+			return entries, fmt.Errorf("could not parse audit log after %d entries: %w", len(entries), err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// tableName will return a readable name of the table with the given url
+func tableName(url string) string {
+
+	for _, table := range Tables() {
+		if table.URL == url {
+			return table.Name
+		}
+	}
+
+	return url
+}
+
+// auditKey will return the key of the record for the audit log. records of the
+// screening tables have no source_id, the key is combined from the source of
+// the table and the id field of the source
+func auditKey(url string, r *Record) string {
+
+	if r.Field("source_id") != "" {
+		return r.Key()
+	}
+
+	for _, source := range Sources {
+		if source.URL != url {
+			continue
+		}
+		if id := r.Field(source.IDField); id != "" {
+			return fmt.Sprintf("%s::%s", source.Name, id)
+		}
+	}
+
+	return r.Key()
+}
+
+// auditChanges will create the audit entries for the given records posted to
+// the table, the current records are used for the values before the write
+func auditChanges(url string, records []*Record, current map[int]*Record,
+	created []Record, requestErr error) []AuditEntry {
+
+	entries := []AuditEntry{}
+	createdIndex := 0
+
+	for _, r := range records {
+
+		entry := AuditEntry{
+			Table:     tableName(url),
+			URL:       url,
+			ID:        r.ID,
+			Operation: AuditUpdate,
+			After:     r.Fields,
+		}
+
+		if r.ID == 0 {
+			entry.Operation = AuditCreate
+
+			// ninox returns the created and updated records in order
+			if createdIndex < len(created) {
+				entry.ID = created[createdIndex].ID
+			}
+		}
+		createdIndex++
+
+		if before, ok := current[r.ID]; ok && r.ID != 0 {
+			entry.Before = make(map[string]interface{})
+			for field := range r.Fields {
+				entry.Before[field] = before.Fields[field]
+			}
+			entry.Key = auditKey(url, before)
+		}
+		if entry.Key == "" {
+			entry.Key = auditKey(url, r)
+		}
+
+		if requestErr != nil {
+			entry.Error = requestErr.Error()
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
@@ -0,0 +1,55 @@
+package ninox
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAuditChangesKey(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		url     string
+		record  *Record
+		current *Record
+		key     string
+	}{
+		{"covebasic", CoveBasicURL,
+			&Record{ID: 1, Fields: map[string]interface{}{"title": "after"}},
+			&Record{ID: 1, Fields: map[string]interface{}{"source": "ICTRP", "source_id": "DRKS00021238"}},
+			"ICTRP::DRKS00021238"},
+		{"clinicaltrials.gov screening", ClinicaltrialsURL,
+			&Record{ID: 2, Fields: map[string]interface{}{"study_type": "Observational"}},
+			&Record{ID: 2, Fields: map[string]interface{}{"nct_id": "NCT04280705"}},
+			"clinicaltrials.gov::NCT04280705"},
+		{"ICTRP screening", IctrpURL,
+			&Record{ID: 3, Fields: map[string]interface{}{"cove_screening": "include"}},
+			&Record{ID: 3, Fields: map[string]interface{}{"TrialID": "ChiCTR2000029308"}},
+			"ICTRP::ChiCTR2000029308"},
+		{"created screening record", MedrxivURL,
+			&Record{Fields: map[string]interface{}{"ID": "10.1101/2020.04.01.20050070"}},
+			nil,
+			"medRxiv::10.1101/2020.04.01.20050070"},
+		{"unknown table", "https://example.org/records",
+			&Record{ID: 4, Fields: map[string]interface{}{"nct_id": "NCT04280705"}},
+			nil,
+			""},
+	}
+
+	for _, test := range tests {
+
+		current := map[int]*Record{}
+		if test.current != nil {
+			current[test.current.ID] = test.current
+		}
+
+		entries := auditChanges(test.url, []*Record{test.record}, current, nil, errors.New("failed"))
+		if len(entries) != 1 {
+			t.Errorf("%s: auditChanges returned %d entries, expected 1", test.name, len(entries))
+			continue
+		}
+		if entries[0].Key != test.key {
+			t.Errorf("%s: key %q, expected %q", test.name, entries[0].Key, test.key)
+		}
+	}
+}
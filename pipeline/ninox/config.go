@@ -4,13 +4,22 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"sync"
 )
 
 var ninoxAPIKey = "MISSING"
 
+// apiKeyOnce ensures that the api key is only requested once
+var apiKeyOnce sync.Once
+
 const CoveBasicURL = "https://api.ninoxdb.de/v1/teams/JaSodfHneNLbnZKHb/databases/bhdh22vn3oqj/tables/A/records"
 const CoveBasicExlusionURL = "https://api.ninoxdb.de/v1/teams/JaSodfHneNLbnZKHb/databases/bhdh22vn3oqj/tables/B/records"
 
+// CoveBasicExtractionURL contains the independent extractions of covebasic
+// records (two records per extracted covebasic record)
+const CoveBasicExtractionURL = "https://api.ninoxdb.de/v1/teams/JaSodfHneNLbnZKHb/databases/bhdh22vn3oqj/tables/C/records"
+
 const ClinicaltrialsURL = "https://api.ninoxdb.de/v1/teams/JaSodfHneNLbnZKHb/databases/ogt4txmvycpz/tables/C/records"
 const IctrpURL = "https://api.ninoxdb.de/v1/teams/JaSodfHneNLbnZKHb/databases/ogt4txmvycpz/tables/E/records"
 const MedrxivURL = "https://api.ninoxdb.de/v1/teams/JaSodfHneNLbnZKHb/databases/ogt4txmvycpz/tables/F/records"
@@ -18,8 +27,100 @@ const SwissethicsURL = "https://api.ninoxdb.de/v1/teams/JaSodfHneNLbnZKHb/databa
 
 const CoveBasicTable = "covebasic"
 const CoveBasicExlusionsTable = "exclusions"
+const CoveBasicExtractionTable = "extractions"
+
+// Source contains information on the screening table of a given source
+type Source struct {
+	Name    string // name of the source in covebasic
+	URL     string // url of the screening table
+	IDField string // name of the field containing the id in the screening table
+
+	// Fields maps common field names (i.e. sponsor) to the corresponding
+	// field in the screening table
+	Fields map[string]string
+}
+
+// Sources contains all sources that are screened for covebasic
+var Sources = []Source{
+	{"clinicaltrials.gov", ClinicaltrialsURL, "nct_id", map[string]string{
+		"sponsor":       "sponsors_agency",
+		"secondary_ids": "secondary_ids",
+		"title":         "official_title",
+		"condition":     "condition",
+		"study_type":    "study_type",
+		"summary":       "brief_summary",
+	}},
+	{"ICTRP", IctrpURL, "TrialID", map[string]string{
+		"sponsor":       "Primary sponsor",
+		"secondary_ids": "Secondary IDs",
+		"title":         "Scientific title",
+		"condition":     "condition",
+		"study_type":    "Study type",
+	}},
+	{"medRxiv", MedrxivURL, "ID", map[string]string{
+		"title":   "rel_title",
+		"summary": "rel_abs",
+	}},
+	{"Ethics committees (CH)", SwissethicsURL, "Project ID", map[string]string{
+		"sponsor":    "Sponsor",
+		"title":      "Project Title",
+		"study_type": "Type of Project",
+	}},
+}
+
+// Table is a table of the covid-evidence databases in ninox
+type Table struct {
+	Name string
+	URL  string
+}
+
+// Tables will return all tables used by the pipeline, covebasic is returned
+// first since the extractions refer to its records
+func Tables() []Table {
+
+	tables := []Table{
+		{CoveBasicTable, CoveBasicURL},
+		{CoveBasicExlusionsTable, CoveBasicExlusionURL},
+		{CoveBasicExtractionTable, CoveBasicExtractionURL},
+	}
+
+	for _, source := range Sources {
+		tables = append(tables, Table{source.Name, source.URL})
+	}
+
+	return tables
+}
+
+// Field will return the value of the given common field from the screening
+// record or an empty string if the source does not provide the field
+func (s Source) Field(r *Record, name string) string {
+	field, ok := s.Fields[name]
+	if !ok {
+		return ""
+	}
+	return r.Field(field)
+}
+
+// SourceByName will return the source with the given name (case insensitive)
+func SourceByName(name string) (Source, bool) {
+	for _, source := range Sources {
+		if strings.EqualFold(source.Name, name) {
+			return source, true
+		}
+	}
+	return Source{}, false
+}
+
+// apiKey will return the ninox api key, which is read from the environment
+// variable NINOX_API_KEY or requested on the first call (i.e. the first request
+// to ninox, so that packages using ninox can be tested without key)
+func apiKey() string {
+	apiKeyOnce.Do(readAPIKey)
+	return ninoxAPIKey
+}
 
-func init() {
+// readAPIKey will read the api key from the environment or the terminal
+func readAPIKey() {
 	fromEnv := os.Getenv("NINOX_API_KEY")
 	if fromEnv != "" {
 		ninoxAPIKey = fromEnv
@@ -27,14 +128,14 @@ func init() {
 	}
 
 	fmt.Println("Enter ninox api key:")
-	var apiKey string
-	_, err := fmt.Scanln(&apiKey)
+	var key string
+	_, err := fmt.Scanln(&key)
 	if err != nil {
 		log.Fatalln("Could not read api key")
 	}
 
-	if apiKey == "" {
+	if key == "" {
 		log.Fatalln("Ninox Api Key is required")
 	}
-	ninoxAPIKey = apiKey
+	ninoxAPIKey = key
 }
@@ -6,9 +6,11 @@ import (
 )
 
 // FetchCoveBasic will fetch all records from covebasic and return an index
-// for source_ids and information if the given id is contained in covebasic/covebasic
-// or covebasic/exclusions
-func FetchCoveBasic(sources ...string) (records []Record, index Index, err error) {
+// for source_ids and registry ids and information if the given id is contained
+// in covebasic/covebasic or covebasic/exclusions (see Index for precedence).
+// ids shared by multiple records can be listed with index.Collisions (see
+// covebasic/id-collisions)
+func FetchCoveBasic(sources ...string) (records []Record, index *Index, err error) {
 
 	// fetch all records from covebasic/covebasic
 	basicIncluded, err := FetchRecords(CoveBasicURL, "")
@@ -25,7 +27,7 @@ func FetchCoveBasic(sources ...string) (records []Record, index Index, err error
 	}
 
 	// index of items in covebasic or covebasic/exclusions
-	index = make(Index)
+	index = NewIndex()
 
 	// contains will check if the source is part of the sources list
 	contains := func(list []string, source string) bool {
@@ -34,7 +36,7 @@ func FetchCoveBasic(sources ...string) (records []Record, index Index, err error
 		source = strings.ToLower(source)
 
 		// return true if no comparison list is provided
-		if list == nil {
+		if len(list) == 0 {
 			return true
 		}
 		for i := range list {
@@ -50,34 +52,18 @@ func FetchCoveBasic(sources ...string) (records []Record, index Index, err error
 		sources[i] = strings.ToLower(sources[i])
 	}
 
-	// check exluded first, so that inclusion takes precedence over exlusions
 	for i, record := range basicExcluded {
-
-		id := record.Field("source_id")
-		source := record.Field("source")
-
-		if !contains(sources, source) {
+		if !contains(sources, record.Field("source")) {
 			continue
 		}
-		index.Set(id, record.ID, CoveBasicExlusionsTable, &basicExcluded[i])
+		index.Add(&basicExcluded[i], CoveBasicExlusionsTable)
 	}
 
 	for i, record := range basicIncluded {
-
-		id := record.Field("source_id")
-		source := record.Field("source")
-
-		if !contains(sources, source) {
+		if !contains(sources, record.Field("source")) {
 			continue
 		}
-
-		// overwrite items contained in exlusions
-		info, ok := index.Get(id)
-		if ok && info.Table == CoveBasicTable {
-			continue
-		}
-
-		index.Set(id, record.ID, CoveBasicTable, &basicIncluded[i])
+		index.Add(&basicIncluded[i], CoveBasicTable)
 	}
 
 	// return the records and the corresponding index
@@ -1,33 +1,177 @@
 package ninox
 
-import "strings"
+import (
+	"fmt"
+	"sort"
+	"strings"
 
-// Index contains information on covebasic inclusions/exclusions
-type Index map[string]RecordInfo
+	"dkfbasel.ch/covid-evidence/registries"
+)
 
+// Index contains information on covebasic inclusions/exclusions. records are
+// indexed by their composite key (source::source_id) and by all their ids
+// (source_id and registry ids), multiple records may share the same id
+//
+// if multiple records are found for an id, the following precedence applies:
+//  1. records in covebasic take precedence over records in the exclusions
+//  2. records matching with their source_id take precedence over records
+//     matching with one of their registry ids
+//  3. records that were created first (lower id) take precedence
+type Index struct {
+	keys map[string][]RecordInfo
+	ids  map[string][]RecordInfo
+}
+
+// RecordInfo contains the information on an indexed record
 type RecordInfo struct {
 	ID     int
 	Table  string
 	Record *Record
+
+	// BySourceID is true if the record was indexed by its source id and false
+	// if it was indexed by one of its registry ids
+	BySourceID bool
 }
 
-// Set will save the given value in the index
-func (i Index) Set(sourceID string, id int, table string, record *Record) {
+// Collision contains all records that share the same id
+type Collision struct {
+	ID      string
+	Records []RecordInfo
+}
+
+// NewIndex will initialize a new empty index
+func NewIndex() *Index {
+	return &Index{
+		keys: make(map[string][]RecordInfo),
+		ids:  make(map[string][]RecordInfo),
+	}
+}
+
+// Add will add the given record from the given table to the index
+func (i *Index) Add(record *Record, table string) {
+
 	info := RecordInfo{
-		ID:     id,
+		ID:     record.ID,
 		Table:  table,
 		Record: record,
 	}
 
-	sourceID = strings.ToLower(sourceID)
-	sourceID = strings.TrimSpace(sourceID)
-	i[sourceID] = info
+	key := normalizeKey(record.Key())
+	if key != "" {
+		i.keys[key] = append(i.keys[key], info)
+	}
+
+	sourceID := record.Field("source_id")
+
+	// index the source id in the original and in the normalized form
+	info.BySourceID = true
+	added := make(map[string]bool)
+	for _, id := range []string{sourceID, registries.Normalize(sourceID)} {
+		id = normalizeKey(id)
+		if id == "" || added[id] {
+			continue
+		}
+		added[id] = true
+		i.ids[id] = append(i.ids[id], info)
+	}
+
+	// index all registry ids of the record
+	info.BySourceID = false
+	for _, id := range registries.Split(record.Field("registry_ids")) {
+		id = normalizeKey(registries.Normalize(id))
+		if id == "" || added[id] {
+			continue
+		}
+		added[id] = true
+		i.ids[id] = append(i.ids[id], info)
+	}
+}
+
+// Get will look up the record with the given id (source id or registry id)
+// and return the record with the highest precedence
+func (i *Index) Get(id string) (RecordInfo, bool) {
+	list := i.GetAll(id)
+	if len(list) == 0 {
+		return RecordInfo{}, false
+	}
+	return list[0], true
+}
+
+// GetAll will return all records with the given id (source id or registry id)
+// ordered by precedence
+func (i *Index) GetAll(id string) []RecordInfo {
+
+	list, ok := i.ids[normalizeKey(id)]
+	if !ok {
+		list = i.ids[normalizeKey(registries.Normalize(id))]
+	}
+
+	sorted := make([]RecordInfo, len(list))
+	copy(sorted, list)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return hasPrecedence(sorted[a], sorted[b])
+	})
+
+	return sorted
+}
+
+// GetKey will look up the record with the given source and source id
+func (i *Index) GetKey(source string, sourceID string) (RecordInfo, bool) {
+
+	key := normalizeKey(fmt.Sprintf("%s::%s", source, sourceID))
+
+	list := i.keys[key]
+	if len(list) == 0 {
+		return RecordInfo{}, false
+	}
+
+	best := list[0]
+	for _, info := range list[1:] {
+		if hasPrecedence(info, best) {
+			best = info
+		}
+	}
+	return best, true
+}
+
+// Len will return the number of composite keys in the index
+func (i *Index) Len() int {
+	return len(i.keys)
+}
+
+// Collisions will return all ids that are shared by multiple records
+func (i *Index) Collisions() []Collision {
+
+	collisions := []Collision{}
+
+	for id := range i.ids {
+		list := i.GetAll(id)
+		if len(list) < 2 {
+			continue
+		}
+		collisions = append(collisions, Collision{ID: id, Records: list})
+	}
+
+	sort.Slice(collisions, func(a, b int) bool {
+		return collisions[a].ID < collisions[b].ID
+	})
+
+	return collisions
+}
+
+// hasPrecedence will check if record a takes precedence over record b
+func hasPrecedence(a, b RecordInfo) bool {
+	if a.Table != b.Table {
+		return a.Table == CoveBasicTable
+	}
+	if a.BySourceID != b.BySourceID {
+		return a.BySourceID
+	}
+	return a.ID < b.ID
 }
 
-// Get will check the index for the given value
-func (i Index) Get(sourceID string) (RecordInfo, bool) {
-	sourceID = strings.ToLower(sourceID)
-	sourceID = strings.TrimSpace(sourceID)
-	info, ok := i[sourceID]
-	return info, ok
+// normalizeKey will convert the given key to lowercase without surrounding
+// whitespace
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
 }
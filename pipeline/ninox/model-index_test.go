@@ -0,0 +1,116 @@
+package ninox
+
+import "testing"
+
+// testRecord will create a record with the given id and fields
+func testRecord(id int, source string, sourceID string, registryIDs string) *Record {
+	return &Record{
+		ID: id,
+		Fields: map[string]interface{}{
+			"source":       source,
+			"source_id":    sourceID,
+			"registry_ids": registryIDs,
+		},
+	}
+}
+
+func TestHasPrecedence(t *testing.T) {
+
+	tests := []struct {
+		name string
+		a    RecordInfo
+		b    RecordInfo
+		want bool
+	}{
+		{"covebasic over exclusions",
+			RecordInfo{ID: 9, Table: CoveBasicTable},
+			RecordInfo{ID: 1, Table: CoveBasicExlusionsTable, BySourceID: true}, true},
+		{"exclusions after covebasic",
+			RecordInfo{ID: 1, Table: CoveBasicExlusionsTable, BySourceID: true},
+			RecordInfo{ID: 9, Table: CoveBasicTable}, false},
+		{"source id over registry id",
+			RecordInfo{ID: 9, Table: CoveBasicTable, BySourceID: true},
+			RecordInfo{ID: 1, Table: CoveBasicTable}, true},
+		{"registry id after source id",
+			RecordInfo{ID: 1, Table: CoveBasicTable},
+			RecordInfo{ID: 9, Table: CoveBasicTable, BySourceID: true}, false},
+		{"older record first",
+			RecordInfo{ID: 1, Table: CoveBasicTable, BySourceID: true},
+			RecordInfo{ID: 9, Table: CoveBasicTable, BySourceID: true}, true},
+		{"newer record last",
+			RecordInfo{ID: 9, Table: CoveBasicTable, BySourceID: true},
+			RecordInfo{ID: 1, Table: CoveBasicTable, BySourceID: true}, false},
+		{"same record",
+			RecordInfo{ID: 1, Table: CoveBasicTable, BySourceID: true},
+			RecordInfo{ID: 1, Table: CoveBasicTable, BySourceID: true}, false},
+	}
+
+	for _, test := range tests {
+		if got := hasPrecedence(test.a, test.b); got != test.want {
+			t.Errorf("%s: hasPrecedence = %v, expected %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestIndexCollisions(t *testing.T) {
+
+	index := NewIndex()
+
+	// added in the order of FetchCoveBasic (exclusions first)
+	index.Add(testRecord(1, "clinicaltrials.gov", "NCT04280705", ""), CoveBasicExlusionsTable)
+	index.Add(testRecord(5, "ICTRP", "EUCTR2020-001366-11", "NCT04280705"), CoveBasicTable)
+	index.Add(testRecord(7, "clinicaltrials.gov", "NCT04280705", ""), CoveBasicTable)
+	index.Add(testRecord(3, "clinicaltrials.gov", "NCT04315948", "EudraCT 2020-000936-23"), CoveBasicTable)
+	index.Add(testRecord(4, "ICTRP", "EUCTR2020-000936-23-FR", ""), CoveBasicExlusionsTable)
+
+	tests := []struct {
+		id    string
+		found bool
+		want  []int
+	}{
+		// covebasic by source id, covebasic by registry id, exclusion
+		{"NCT04280705", true, []int{7, 5, 1}},
+		{"nct 04280705", true, []int{7, 5, 1}},
+		{"EUCTR2020-001366-11", true, []int{5}},
+		// registry id in covebasic takes precedence over the exclusion
+		{"2020-000936-23", true, []int{3, 4}},
+		{"NCT00000000", false, []int{}},
+	}
+
+	for _, test := range tests {
+
+		info, ok := index.Get(test.id)
+		if ok != test.found {
+			t.Errorf("Get(%q) found %v, expected %v", test.id, ok, test.found)
+			continue
+		}
+		if ok && info.ID != test.want[0] {
+			t.Errorf("Get(%q) = %d, expected %d", test.id, info.ID, test.want[0])
+		}
+
+		all := index.GetAll(test.id)
+		if len(all) != len(test.want) {
+			t.Errorf("GetAll(%q) returned %d records, expected %d", test.id, len(all), len(test.want))
+			continue
+		}
+		for i := range all {
+			if all[i].ID != test.want[i] {
+				t.Errorf("GetAll(%q)[%d] = %d, expected %d", test.id, i, all[i].ID, test.want[i])
+			}
+		}
+	}
+
+	// the composite key is shared by the inclusion and the exclusion
+	info, ok := index.GetKey("clinicaltrials.gov", "NCT04280705")
+	if !ok || info.ID != 7 {
+		t.Errorf("GetKey returned %d (%v), expected 7", info.ID, ok)
+	}
+
+	collisions := index.Collisions()
+	if len(collisions) != 2 {
+		t.Fatalf("Collisions returned %d ids, expected 2: %+v", len(collisions), collisions)
+	}
+	if collisions[0].ID != "euctr2020-000936-23" || collisions[1].ID != "nct04280705" {
+		t.Errorf("unexpected collisions: %s, %s", collisions[0].ID, collisions[1].ID)
+	}
+}
@@ -0,0 +1,133 @@
+package queries
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Query is a named and versioned search query of a source. published versions
+// must not be changed, a changed search must be added as new version
+type Query struct {
+	Source      string `json:"source"` // name of the source in covebasic
+	Name        string `json:"name"`
+	Version     int    `json:"version"`
+	Expression  string `json:"expression"` // search expression as expected by the source
+	Description string `json:"description"`
+}
+
+// ConfigPath will return the path of the query definitions, which can be set
+// with the environment variable COVE_QUERIES (defaults to queries.json next to
+// the source of this package, which is versioned with the code)
+func ConfigPath() string {
+
+	if path := os.Getenv("COVE_QUERIES"); path != "" {
+		return path
+	}
+
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return "queries.json"
+	}
+	return filepath.Join(filepath.Dir(file), "queries.json")
+}
+
+// Load will load the query definitions from the given file. the definitions
+// must be complete and each version of a query may only be defined once
+func Load(path string) ([]Query, error) {
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read query definitions: %w", err)
+	}
+
+	list := []Query{}
+	err = json.Unmarshal(content, &list)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse query definitions %s: %w", path, err)
+	}
+
+	ids := make(map[string]bool)
+	for _, q := range list {
+
+		if q.Source == "" || q.Name == "" || q.Version < 1 || q.Expression == "" {
+			return nil, fmt.Errorf("incomplete query definition: %s", q.ID())
+		}
+
+		id := strings.ToLower(q.ID())
+		if ids[id] {
+			return nil, fmt.Errorf("query is defined twice: %s", q.ID())
+		}
+		ids[id] = true
+	}
+
+	return list, nil
+}
+
+// ID will return the identifier of the query (i.e. clinicaltrials.gov/covid@1)
+func (q Query) ID() string {
+	return fmt.Sprintf("%s/%s@%d", q.Source, q.Name, q.Version)
+}
+
+// Get will return the query of the given source with the given name and
+// version. the version 0 returns the latest version of the query
+func Get(source string, name string, version int) (Query, error) {
+
+	list, err := Load(ConfigPath())
+	if err != nil {
+		return Query{}, err
+	}
+
+	var found *Query
+
+	for i, q := range list {
+		if !strings.EqualFold(q.Source, source) || !strings.EqualFold(q.Name, name) {
+			continue
+		}
+		if version != 0 && q.Version != version {
+			continue
+		}
+		if found == nil || q.Version > found.Version {
+			found = &list[i]
+		}
+	}
+
+	if found == nil {
+		return Query{}, fmt.Errorf("could not find query %s/%s@%d", source, name, version)
+	}
+
+	return *found, nil
+}
+
+// Latest will return the latest version of the query with the given name
+func Latest(source string, name string) (Query, error) {
+	return Get(source, name, 0)
+}
+
+// Parse will return the query with the given identifier. the version may be
+// omitted to select the latest version (i.e. clinicaltrials.gov/covid)
+func Parse(id string) (Query, error) {
+
+	i := strings.LastIndex(id, "/")
+	if i == -1 {
+		return Query{}, fmt.Errorf("invalid query id, expected source/name@version: %s", id)
+	}
+
+	source := id[:i]
+	name := id[i+1:]
+	version := 0
+
+	if j := strings.Index(name, "@"); j != -1 {
+		_, err := fmt.Sscanf(name[j+1:], "%d", &version)
+		if err != nil {
+			return Query{}, fmt.Errorf("invalid query version: %s", id)
+		}
+		name = name[:j]
+	}
+
+	return Get(source, name, version)
+}
@@ -0,0 +1,85 @@
+package queries
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+
+	tests := []struct {
+		id string
+		ok bool
+	}{
+		{"clinicaltrials.gov/covid@1", true},
+		{"clinicaltrials.gov/covid", true},
+		{"medrxiv/COVID", true},
+		{"clinicaltrials.gov/covid@99", false},
+		{"ICTRP/covid", false},
+		{"covid", false},
+	}
+
+	for _, test := range tests {
+		q, err := Parse(test.id)
+		if (err == nil) != test.ok {
+			t.Errorf("Parse(%q) returned %v, expected ok %v", test.id, err, test.ok)
+			continue
+		}
+		if err == nil && q.Expression == "" {
+			t.Errorf("Parse(%q) returned a query without expression", test.id)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "queries")
+	if err != nil {
+		t.Fatalf("could not create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir) // nolint:errcheck
+
+	tests := []struct {
+		name    string
+		content string
+		ok      bool
+	}{
+		{"valid", `[{"source": "ICTRP", "name": "covid", "version": 1, "expression": "covid"},
+			{"source": "ICTRP", "name": "covid", "version": 2, "expression": "covid OR sars-cov-2"}]`, true},
+		{"defined twice", `[{"source": "ICTRP", "name": "covid", "version": 1, "expression": "covid"},
+			{"source": "ictrp", "name": "covid", "version": 1, "expression": "sars-cov-2"}]`, false},
+		{"without version", `[{"source": "ICTRP", "name": "covid", "expression": "covid"}]`, false},
+		{"without expression", `[{"source": "ICTRP", "name": "covid", "version": 1}]`, false},
+		{"invalid json", `[{"source": "ICTRP"`, false},
+	}
+
+	for _, test := range tests {
+
+		path := filepath.Join(dir, "queries.json")
+		err := ioutil.WriteFile(path, []byte(test.content), 0644)
+		if err != nil {
+			t.Fatalf("could not write query definitions: %v", err)
+		}
+
+		_, err = Load(path)
+		if (err == nil) != test.ok {
+			t.Errorf("%s: Load returned %v, expected ok %v", test.name, err, test.ok)
+		}
+	}
+
+	// the definitions can be replaced for a run
+	os.Setenv("COVE_QUERIES", filepath.Join(dir, "queries.json")) // nolint:errcheck
+	defer os.Unsetenv("COVE_QUERIES")                             // nolint:errcheck
+
+	err = ioutil.WriteFile(filepath.Join(dir, "queries.json"), []byte(tests[0].content), 0644)
+	if err != nil {
+		t.Fatalf("could not write query definitions: %v", err)
+	}
+
+	q, err := Latest("ICTRP", "covid")
+	if err != nil || q.Version != 2 {
+		t.Errorf("Latest returned %s (%v), expected ICTRP/covid@2", q.ID(), err)
+	}
+}
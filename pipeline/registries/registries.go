@@ -0,0 +1,110 @@
+package registries
+
+import (
+	"regexp"
+	"strings"
+)
+
+// registries contains the patterns to identify the trial ids of all supported
+// registries. the normalize function converts the match into the normalized
+// form of the id, which is used for comparison
+var registries = []struct {
+	Name      string
+	Pattern   *regexp.Regexp
+	Normalize func(match []string) string
+}{
+	{"clinicaltrials.gov", regexp.MustCompile(`(?i)\bNCT\s?(\d{8})\b`), prefixed("NCT")},
+	// eudract numbers are often listed without prefix, these are only matched
+	// at the start of the text or after a separator (i.e. not within dates
+	// or numbers of other registries)
+	{"EudraCT", regexp.MustCompile(`(?i)(?:\bEUCTR|\bEudraCT(?:\s*(?:no\.?|number|nr\.?))?[\s:#-]*|^|[\s;,(\[])(\d{4}-\d{6}-\d{2})(?:-[A-Z]{2})?\b`), prefixed("EUCTR")},
+	{"ChiCTR", regexp.MustCompile(`(?i)\bChiCTR-?([A-Z]{0,5}-?\d{8,10})\b`), func(match []string) string {
+		// older ids contain the type of the trial (i.e. ChiCTR-IOR-17012345)
+		number := strings.ToUpper(match[1])
+		if number[0] < '0' || number[0] > '9' {
+			return "ChiCTR-" + number
+		}
+		return "ChiCTR" + number
+	}},
+	{"ISRCTN", regexp.MustCompile(`(?i)\bISRCTN\s?(\d{8})\b`), prefixed("ISRCTN")},
+	{"DRKS", regexp.MustCompile(`(?i)\bDRKS\s?(\d{8})\b`), prefixed("DRKS")},
+	{"BASEC", regexp.MustCompile(`(?i)\bBASEC(?:\s*(?:no\.?|nr\.?|id|number))?[\s:#]*(20\d{2}-\d{5})\b`), prefixed("BASEC")},
+	{"IRCT", regexp.MustCompile(`(?i)\bIRCT(\d{8,}N\d+)\b`), prefixed("IRCT")},
+	{"CTRI", regexp.MustCompile(`(?i)\bCTRI/(\d{4}/\d{2,3}/\d{6})\b`), prefixed("CTRI/")},
+	{"JPRN", regexp.MustCompile(`(?i)\bJPRN-([A-Z]+\d+)\b`), prefixed("JPRN-")},
+	{"ANZCTR", regexp.MustCompile(`(?i)\bACTRN(\d{14})\b`), prefixed("ACTRN")},
+	{"NTR", regexp.MustCompile(`(?i)\bNTR(\d{3,5})\b`), prefixed("NTR")},
+	{"CRIS", regexp.MustCompile(`(?i)\bKCT(\d{7})\b`), prefixed("KCT")},
+	{"TCTR", regexp.MustCompile(`(?i)\bTCTR(\d{11})\b`), prefixed("TCTR")},
+	{"PACTR", regexp.MustCompile(`(?i)\bPACTR(\d{15})\b`), prefixed("PACTR")},
+	{"ReBec", regexp.MustCompile(`(?i)\bRBR-(\w{6,8})\b`), prefixed("RBR-")},
+	{"LBCTR", regexp.MustCompile(`(?i)\bLBCTR(\d{10})\b`), prefixed("LBCTR")},
+	{"RPCEC", regexp.MustCompile(`(?i)\bRPCEC(\d{8})\b`), prefixed("RPCEC")},
+}
+
+// prefixed will return a normalize function, that combines the given prefix
+// with the uppercased number of the id
+func prefixed(prefix string) func(match []string) string {
+	return func(match []string) string {
+		return prefix + strings.ToUpper(match[1])
+	}
+}
+
+// Extract will return the normalized ids of all registries found in the text
+func Extract(text string) []string {
+
+	ids := []string{}
+	seen := make(map[string]bool)
+
+	for _, registry := range registries {
+		for _, match := range registry.Pattern.FindAllStringSubmatch(text, -1) {
+			id := registry.Normalize(match)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// Normalize will return the normalized form of the given id or the trimmed id
+// if it does not match any registry
+func Normalize(id string) string {
+	ids := Extract(id)
+	if len(ids) == 1 {
+		return ids[0]
+	}
+	return strings.TrimSpace(id)
+}
+
+// Registry will return the name of the registry of the given id or an empty
+// string if the registry is unknown
+func Registry(id string) string {
+	for _, registry := range registries {
+		if registry.Pattern.MatchString(id) {
+			return registry.Name
+		}
+	}
+	return ""
+}
+
+// List will extract the ids from all given values and return them as
+// semicolon separated list
+func List(values ...string) string {
+	return strings.Join(Extract(strings.Join(values, "; ")), "; ")
+}
+
+// Split will split the given list of ids (i.e. the registry_ids field)
+func Split(value string) []string {
+	ids := []string{}
+	for _, id := range strings.Split(value, ";") {
+		id = strings.TrimSpace(id)
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
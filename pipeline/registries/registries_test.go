@@ -0,0 +1,92 @@
+package registries
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+
+	tests := []struct {
+		text string
+		ids  []string
+	}{
+		{"", nil},
+		{"NCT04315948", []string{"NCT04315948"}},
+		{"nct 04315948", []string{"NCT04315948"}},
+		{"see NCT04315948 and NCT04280705", []string{"NCT04315948", "NCT04280705"}},
+		{"NCT04315948; NCT04315948", []string{"NCT04315948"}},
+
+		// eudract numbers with and without prefix
+		{"EUCTR2020-000936-23-FR", []string{"EUCTR2020-000936-23"}},
+		{"EudraCT 2020-000936-23", []string{"EUCTR2020-000936-23"}},
+		{"EudraCT number: 2020-000936-23", []string{"EUCTR2020-000936-23"}},
+		{"EudraCT-2020-000936-23", []string{"EUCTR2020-000936-23"}},
+		{"2020-000936-23", []string{"EUCTR2020-000936-23"}},
+		{"2020-000936-23 2020-001113-21", []string{"EUCTR2020-000936-23", "EUCTR2020-001113-21"}},
+		{"NCT04315948; 2020-000936-23", []string{"NCT04315948", "EUCTR2020-000936-23"}},
+		{"(2020-000936-23)", []string{"EUCTR2020-000936-23"}},
+
+		// numbers within other identifiers are not eudract numbers
+		{"X2020-000936-23", nil},
+		{"ABC-2020-000936-23", nil},
+		{"ref/2020-000936-23", nil},
+
+		{"ChiCTR2000029308", []string{"ChiCTR2000029308"}},
+		{"ChiCTR-IOR-17012345", []string{"ChiCTR-IOR-17012345"}},
+		{"ISRCTN83971151", []string{"ISRCTN83971151"}},
+		{"DRKS00021238", []string{"DRKS00021238"}},
+		{"BASEC 2020-00873", []string{"BASEC2020-00873"}},
+		{"BASEC Nr: 2020-00873", []string{"BASEC2020-00873"}},
+		{"IRCT20200318046812N2", []string{"IRCT20200318046812N2"}},
+		{"CTRI/2020/04/024773", []string{"CTRI/2020/04/024773"}},
+		{"JPRN-jRCT2031190264", []string{"JPRN-JRCT2031190264"}},
+		{"ACTRN12620000445976", []string{"ACTRN12620000445976"}},
+		{"KCT0005157", []string{"KCT0005157"}},
+		{"RBR-4mzxz7", []string{"RBR-4MZXZ7"}},
+	}
+
+	for _, test := range tests {
+		ids := Extract(test.text)
+		if strings.Join(ids, "; ") != strings.Join(test.ids, "; ") {
+			t.Errorf("Extract(%q) = %v, expected %v", test.text, ids, test.ids)
+		}
+	}
+}
+
+func TestNormalizeAndRegistry(t *testing.T) {
+
+	tests := []struct {
+		id         string
+		normalized string
+		registry   string
+	}{
+		{"nct04315948", "NCT04315948", "clinicaltrials.gov"},
+		{"EudraCT 2020-000936-23", "EUCTR2020-000936-23", "EudraCT"},
+		{"2020-000936-23", "EUCTR2020-000936-23", "EudraCT"},
+		{" BASEC 2020-00873 ", "BASEC2020-00873", "BASEC"},
+		{" unknown-123 ", "unknown-123", ""},
+	}
+
+	for _, test := range tests {
+		if normalized := Normalize(test.id); normalized != test.normalized {
+			t.Errorf("Normalize(%q) = %q, expected %q", test.id, normalized, test.normalized)
+		}
+		if registry := Registry(test.id); registry != test.registry {
+			t.Errorf("Registry(%q) = %q, expected %q", test.id, registry, test.registry)
+		}
+	}
+}
+
+func TestListAndSplit(t *testing.T) {
+
+	list := List("NCT04315948", "", "EudraCT 2020-000936-23; NCT04315948")
+	if list != "NCT04315948; EUCTR2020-000936-23" {
+		t.Errorf("List = %q, expected %q", list, "NCT04315948; EUCTR2020-000936-23")
+	}
+
+	ids := Split(" NCT04315948 ;; EUCTR2020-000936-23; ")
+	if strings.Join(ids, "|") != "NCT04315948|EUCTR2020-000936-23" {
+		t.Errorf("Split = %v, expected [NCT04315948 EUCTR2020-000936-23]", ids)
+	}
+}
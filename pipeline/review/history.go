@@ -0,0 +1,52 @@
+package review
+
+import "strings"
+
+// Change is a transition of the review status recorded in the history of a
+// covebasic record (field review_status_history)
+type Change struct {
+	Date     string
+	Status   Status
+	Operator string
+}
+
+// AppendHistory will append the given change to the history, each change is
+// stored on a separate line as date;status;operator
+func AppendHistory(history string, change Change) string {
+
+	line := strings.Join([]string{change.Date, string(change.Status), change.Operator}, ";")
+
+	history = strings.TrimSpace(history)
+	if history == "" {
+		return line
+	}
+	return history + "\n" + line
+}
+
+// ParseHistory will return all changes of the given history. lines with
+// unknown status are skipped
+func ParseHistory(history string) []Change {
+
+	changes := []Change{}
+
+	for _, line := range strings.Split(history, "\n") {
+
+		parts := strings.SplitN(strings.TrimSpace(line), ";", 3)
+		if len(parts) < 2 {
+			continue
+		}
+
+		state, ok := Parse(parts[1])
+		if !ok {
+			continue
+		}
+
+		change := Change{Date: strings.TrimSpace(parts[0]), Status: state}
+		if len(parts) == 3 {
+			change.Operator = strings.TrimSpace(parts[2])
+		}
+		changes = append(changes, change)
+	}
+
+	return changes
+}
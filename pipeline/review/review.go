@@ -0,0 +1,96 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Status is a state of the review workflow of a covebasic record
+type Status string
+
+// states of the review workflow, prefilled keeps the value used before the
+// workflow was defined
+const (
+	Prefilled    Status = "prefilled automatically"
+	InExtraction Status = "in extraction"
+	Extracted    Status = "extracted"
+	Verified     Status = "verified"
+	Locked       Status = "locked"
+)
+
+// States contains all states in the order of the workflow
+var States = []Status{Prefilled, InExtraction, Extracted, Verified, Locked}
+
+// transitions contains the allowed transitions of each state. records can be
+// sent back to extraction if issues are found, locked records can only be
+// unlocked to verified
+var transitions = map[Status][]Status{
+	Prefilled:    {InExtraction},
+	InExtraction: {Extracted, Prefilled},
+	Extracted:    {Verified, InExtraction},
+	Verified:     {Locked, InExtraction},
+	Locked:       {Verified},
+}
+
+// aliases maps the values used in ninox before the workflow was defined to
+// the corresponding state (see the export of 2020-05-18)
+var aliases = map[string]Status{
+	"in manual extraction":        InExtraction,
+	"manual extraction completed": Extracted,
+	"review completed":            Verified,
+}
+
+// Parse will return the state of the given review status, the status is
+// compared case insensitive and known aliases are accepted
+func Parse(value string) (Status, bool) {
+
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	for _, state := range States {
+		if value == string(state) {
+			return state, true
+		}
+	}
+
+	state, ok := aliases[value]
+	return state, ok
+}
+
+// Automated will check if a record with the given review status may be
+// changed by automated steps (i.e. imports from the sources or fixes of single
+// fields), which is only the case for prefilled records. records with unknown
+// status are not changed
+func Automated(value string) bool {
+	state, ok := Parse(value)
+	return ok && state == Prefilled
+}
+
+// CanTransition will check if the transition between the states is allowed
+func CanTransition(from Status, to Status) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition will check the transition from the given review status to the
+// given state and return the new state
+func Transition(value string, to Status) (Status, error) {
+
+	from, ok := Parse(value)
+	if !ok {
+		return "", fmt.Errorf("unknown review status: %q", value)
+	}
+
+	if from == to {
+		return to, nil
+	}
+
+	if !CanTransition(from, to) {
+		return "", fmt.Errorf("transition from %q to %q is not allowed", from, to)
+	}
+
+	return to, nil
+}
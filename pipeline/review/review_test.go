@@ -0,0 +1,106 @@
+package review
+
+import "testing"
+
+func TestParse(t *testing.T) {
+
+	// values of review_status in the export of 2020-05-18
+	tests := []struct {
+		value string
+		state Status
+		ok    bool
+	}{
+		{"prefilled automatically", Prefilled, true},
+		{"manual extraction completed", Extracted, true},
+		{"review completed", Verified, true},
+		{"in manual extraction", InExtraction, true},
+		{" Review Completed ", Verified, true},
+		{"locked", Locked, true},
+		{"done", "", false},
+		{"final", "", false},
+		{"", "", false},
+	}
+
+	for _, test := range tests {
+		state, ok := Parse(test.value)
+		if state != test.state || ok != test.ok {
+			t.Errorf("Parse(%q) = %q, %v, expected %q, %v", test.value, state, ok, test.state, test.ok)
+		}
+	}
+}
+
+func TestAutomated(t *testing.T) {
+
+	tests := []struct {
+		value     string
+		automated bool
+	}{
+		{"prefilled automatically", true},
+		{"in manual extraction", false},
+		{"manual extraction completed", false},
+		{"review completed", false},
+		{"locked", false},
+		{"unknown value", false},
+	}
+
+	for _, test := range tests {
+		if automated := Automated(test.value); automated != test.automated {
+			t.Errorf("Automated(%q) = %v, expected %v", test.value, automated, test.automated)
+		}
+	}
+}
+
+func TestTransition(t *testing.T) {
+
+	tests := []struct {
+		value string
+		to    Status
+		ok    bool
+	}{
+		{"prefilled automatically", InExtraction, true},
+		{"prefilled automatically", Verified, false},
+		{"manual extraction completed", Verified, true},
+		{"review completed", Locked, true},
+		{"locked", Prefilled, false},
+		{"locked", Verified, true},
+		{"review completed", Verified, true},
+		{"done", Verified, false},
+	}
+
+	for _, test := range tests {
+		_, err := Transition(test.value, test.to)
+		if (err == nil) != test.ok {
+			t.Errorf("Transition(%q, %q) returned error %v, expected ok %v", test.value, test.to, err, test.ok)
+		}
+	}
+}
+
+func TestHistory(t *testing.T) {
+
+	history := AppendHistory("", Change{"2020-06-01", InExtraction, "anna"})
+	history = AppendHistory(history, Change{"2020-06-15", Extracted, "anna"})
+	history = AppendHistory(history+"\n", Change{"2020-07-02", Verified, ""})
+
+	expected := "2020-06-01;in extraction;anna\n2020-06-15;extracted;anna\n2020-07-02;verified;"
+	if history != expected {
+		t.Errorf("AppendHistory = %q, expected %q", history, expected)
+	}
+
+	changes := ParseHistory(history + "\n\n2020-07-03;done;anna\n2020-07-04;review completed")
+
+	expectedChanges := []Change{
+		{"2020-06-01", InExtraction, "anna"},
+		{"2020-06-15", Extracted, "anna"},
+		{"2020-07-02", Verified, ""},
+		{"2020-07-04", Verified, ""},
+	}
+
+	if len(changes) != len(expectedChanges) {
+		t.Fatalf("ParseHistory returned %d changes, expected %d", len(changes), len(expectedChanges))
+	}
+	for i := range changes {
+		if changes[i] != expectedChanges[i] {
+			t.Errorf("ParseHistory: change %d = %+v, expected %+v", i, changes[i], expectedChanges[i])
+		}
+	}
+}
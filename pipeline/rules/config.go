@@ -0,0 +1,50 @@
+package rules
+
+// Version identifies the current definition of the rules, it must be changed
+// whenever a rule is added, removed or modified
+const Version = "2020-07-01"
+
+// Exclusion contains the rules to move records from covebasic to the
+// exclusions table
+var Exclusion = Set{
+	{
+		Name:        "not-covid",
+		Description: "record is not related to covid-19",
+		Expression:  `is_covid == "no"`,
+	},
+	{
+		Name:        "not-trial",
+		Description: "record is not an interventional trial",
+		Expression:  `is_trial == "no"`,
+	},
+	{
+		Name:        "duplicate",
+		Description: "record is a duplicate of another record",
+		Expression:  `is_duplicate == "true"`,
+	},
+}
+
+// Inclusion contains the rules to move records from the exclusions table back
+// to covebasic (only if no exclusion rule applies)
+var Inclusion = Set{
+	{
+		Name:        "eligible",
+		Description: "record was assessed as covid-19 trial without duplicate",
+		Expression:  `is_covid == "yes" and is_trial == "yes" and is_duplicate == "false"`,
+	},
+}
+
+// Publication contains additional rules for records that are not published on
+// the website (in addition to the exclusion rules)
+var Publication = Set{
+	{
+		Name:        "no-source",
+		Description: "record does not have a source",
+		Expression:  `source == ""`,
+	},
+	{
+		Name:        "no-cove-id",
+		Description: "record does not have a cove id",
+		Expression:  `cove_id in ["", "0"]`,
+	},
+}
@@ -0,0 +1,302 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// the expressions of the rules use a small language to compare fields of a
+// record with constant values, i.e.
+//
+//	is_trial == "no" and is_observational in ["no", "unclear", "yes"]
+//
+// supported are the comparisons ==, != and in, the logical operators and, or,
+// not and parentheses. all comparisons are case insensitive and ignore
+// surrounding whitespace, missing fields are considered to be empty
+
+// node is a compiled expression that can be evaluated against a record
+type node interface {
+	eval(field func(name string) string) bool
+}
+
+type andNode struct{ left, right node }
+type orNode struct{ left, right node }
+type notNode struct{ operand node }
+
+// compareNode compares a field with a list of values (a single value for ==
+// and !=), negate is used for !=
+type compareNode struct {
+	field  string
+	values []string
+	negate bool
+}
+
+func (n andNode) eval(field func(string) string) bool {
+	return n.left.eval(field) && n.right.eval(field)
+}
+
+func (n orNode) eval(field func(string) string) bool {
+	return n.left.eval(field) || n.right.eval(field)
+}
+
+func (n notNode) eval(field func(string) string) bool {
+	return !n.operand.eval(field)
+}
+
+func (n compareNode) eval(field func(string) string) bool {
+	value := strings.TrimSpace(field(n.field))
+	for _, v := range n.values {
+		if strings.EqualFold(value, v) {
+			return !n.negate
+		}
+	}
+	return n.negate
+}
+
+// token types of the lexer
+const (
+	tokenEOF = iota
+	tokenIdent
+	tokenString
+	tokenOperator
+)
+
+type token struct {
+	kind  int
+	value string
+	pos   int
+}
+
+// tokenize will split the expression into tokens
+func tokenize(expression string) ([]token, error) {
+
+	tokens := []token{}
+	runes := []rune(expression)
+
+	for i := 0; i < len(runes); {
+
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '"':
+			// string literal, quotes can be escaped with a backslash
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != '"'; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				b.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			tokens = append(tokens, token{tokenString, b.String(), i})
+			i = j + 1
+
+		case r == '=' || r == '!':
+			if i+1 >= len(runes) || runes[i+1] != '=' {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+			}
+			tokens = append(tokens, token{tokenOperator, string(runes[i : i+2]), i})
+			i += 2
+
+		case strings.ContainsRune("()[],", r):
+			tokens = append(tokens, token{tokenOperator, string(r), i})
+			i++
+
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.':
+			// identifiers (field names, keywords) and unquoted numbers
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) ||
+				runes[j] == '_' || runes[j] == '-' || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{tokenIdent, string(runes[i:j]), i})
+			i = j
+
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+		}
+	}
+
+	tokens = append(tokens, token{tokenEOF, "", len(runes)})
+	return tokens, nil
+}
+
+// parser is a recursive descent parser for the expressions
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse will compile the given expression
+func parse(expression string) (node, error) {
+
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.peek().value, p.peek().pos)
+	}
+
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// isKeyword will check if the next token is the given keyword
+func (p *parser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenIdent && strings.EqualFold(t.value, keyword)
+}
+
+// expect will consume the next token if it is the given operator
+func (p *parser) expect(operator string) error {
+	t := p.next()
+	if t.kind != tokenOperator || t.value != operator {
+		return fmt.Errorf("expected %q at position %d", operator, t.pos)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+
+	if p.isKeyword("not") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+
+	if t := p.peek(); t.kind == tokenOperator && t.value == "(" {
+		p.next()
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expect(")")
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+
+	field := p.next()
+	if field.kind != tokenIdent {
+		return nil, fmt.Errorf("expected field name at position %d", field.pos)
+	}
+
+	if p.isKeyword("in") {
+		p.next()
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{field: field.value, values: values}, nil
+	}
+
+	operator := p.next()
+	if operator.kind != tokenOperator || (operator.value != "==" && operator.value != "!=") {
+		return nil, fmt.Errorf("expected comparison at position %d", operator.pos)
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return compareNode{field: field.value, values: []string{value}, negate: operator.value == "!="}, nil
+}
+
+func (p *parser) parseList() ([]string, error) {
+
+	err := p.expect("[")
+	if err != nil {
+		return nil, err
+	}
+
+	values := []string{}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		t := p.next()
+		if t.kind == tokenOperator && t.value == "]" {
+			return values, nil
+		}
+		if t.kind != tokenOperator || t.value != "," {
+			return nil, fmt.Errorf("expected \",\" or \"]\" at position %d", t.pos)
+		}
+	}
+}
+
+// parseValue will parse a string literal or an unquoted value (i.e. numbers
+// or true/false), which are compared as strings
+func (p *parser) parseValue() (string, error) {
+	t := p.next()
+	if t.kind != tokenString && t.kind != tokenIdent {
+		return "", fmt.Errorf("expected value at position %d", t.pos)
+	}
+	return strings.TrimSpace(t.value), nil
+}
@@ -0,0 +1,108 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"dkfbasel.ch/covid-evidence/helpers"
+)
+
+// Rule is a named condition on the fields of a covebasic record
+type Rule struct {
+	Name        string
+	Description string
+	Expression  string
+
+	compiled node
+}
+
+// Set is an ordered list of rules, the first matching rule is reported
+type Set []*Rule
+
+// Compile will parse the expressions of all rules of the set
+func (s Set) Compile() error {
+	for _, rule := range s {
+		compiled, err := parse(rule.Expression)
+		if err != nil {
+			return fmt.Errorf("could not compile rule %s: %w", rule.Name, err)
+		}
+		rule.compiled = compiled
+	}
+	return nil
+}
+
+// Matches will check if the rule applies to the record with the given fields
+func (r *Rule) Matches(field func(name string) string) bool {
+	if r.compiled == nil {
+		compiled, err := parse(r.Expression)
+		if err != nil {
+			// rules are compiled on initialization, this should not happen
+			panic(fmt.Sprintf("invalid rule %s: %v", r.Name, err))
+		}
+		r.compiled = compiled
+	}
+	return r.compiled.eval(field)
+}
+
+// Match will return the first rule of the set that applies to the record
+func (s Set) Match(field func(name string) string) (*Rule, bool) {
+	for _, rule := range s {
+		if rule.Matches(field) {
+			return rule, true
+		}
+	}
+	return nil, false
+}
+
+// Fields will return a field accessor for generic records (i.e. records
+// decoded from json)
+func Fields(record map[string]interface{}) func(name string) string {
+	return func(name string) string {
+		return helpers.AsString(record[name])
+	}
+}
+
+// Excluded will return the exclusion rule that applies to the record
+func Excluded(field func(name string) string) (*Rule, bool) {
+	return Exclusion.Match(field)
+}
+
+// Included will return the inclusion rule that applies to the record. records
+// are only included if no exclusion rule applies
+func Included(field func(name string) string) (*Rule, bool) {
+	if _, excluded := Exclusion.Match(field); excluded {
+		return nil, false
+	}
+	return Inclusion.Match(field)
+}
+
+// Restored will check if the record of the exclusions table was moved back to
+// covebasic, i.e. if its inclusion date is not before its (last) exclusion date.
+// records excluded again keep the inclusion date, but have a later exclusion date
+func Restored(field func(name string) string) bool {
+
+	included := isoDate(field("inclusion_date"))
+	if included == "" {
+		return false
+	}
+
+	return included >= isoDate(field("exclusion_date"))
+}
+
+// isoDate will return the date part of an iso date or timestamp
+func isoDate(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) > len("2006-01-02") {
+		return value[:len("2006-01-02")]
+	}
+	return value
+}
+
+func init() {
+	for _, set := range []Set{Exclusion, Inclusion, Publication} {
+		err := set.Compile()
+		if err != nil {
+			panic(err)
+		}
+	}
+}
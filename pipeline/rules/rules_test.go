@@ -0,0 +1,127 @@
+package rules
+
+import "testing"
+
+func TestParse(t *testing.T) {
+
+	record := Fields(map[string]interface{}{
+		"is_trial":         "No",
+		"is_covid":         " yes ",
+		"is_observational": "unclear",
+		"cove_id":          0,
+		"title":            `Trial "A"`,
+	})
+
+	tests := []struct {
+		expression string
+		matches    bool
+	}{
+		{`is_trial == "no"`, true},
+		{`is_trial == no`, true},
+		{`is_trial != "no"`, false},
+		{`is_covid == "yes"`, true},
+		{`missing == ""`, true},
+		{`missing != ""`, false},
+		{`cove_id == 0`, true},
+		{`cove_id in ["", "0"]`, true},
+		{`is_observational in ["no", "yes"]`, false},
+		{`title == "Trial \"A\""`, true},
+		{`is_trial == "no" and is_covid == "no"`, false},
+		{`is_trial == "no" or is_covid == "no"`, true},
+		{`not is_trial == "no"`, false},
+		{`NOT (is_trial == "yes" OR is_covid == "no")`, true},
+		{`is_covid == "no" or is_trial == "no" and is_observational == "no"`, false},
+		{`(is_covid == "no" or is_trial == "no") and is_observational == "unclear"`, true},
+	}
+
+	for _, test := range tests {
+		n, err := parse(test.expression)
+		if err != nil {
+			t.Errorf("parse(%q) returned %v", test.expression, err)
+			continue
+		}
+		if matches := n.eval(record); matches != test.matches {
+			t.Errorf("parse(%q) evaluates to %v, expected %v", test.expression, matches, test.matches)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+
+	expressions := []string{
+		``,
+		`is_trial`,
+		`is_trial = "no"`,
+		`is_trial == "no`,
+		`is_trial == "no" and`,
+		`is_trial in "no"`,
+		`is_trial in ["no" "yes"]`,
+		`(is_trial == "no"`,
+		`is_trial == "no")`,
+		`is_trial == "no" # comment`,
+	}
+
+	for _, expression := range expressions {
+		if _, err := parse(expression); err == nil {
+			t.Errorf("parse(%q) returned no error", expression)
+		}
+	}
+}
+
+func TestExcludedAndIncluded(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		record   map[string]interface{}
+		excluded string
+		included string
+	}{
+		{"eligible", map[string]interface{}{"is_covid": "yes", "is_trial": "yes", "is_duplicate": "false"}, "", "eligible"},
+		{"not covid", map[string]interface{}{"is_covid": "no", "is_trial": "yes", "is_duplicate": "false"}, "not-covid", ""},
+		{"not trial", map[string]interface{}{"is_covid": "yes", "is_trial": "no"}, "not-trial", ""},
+		{"duplicate", map[string]interface{}{"is_covid": "yes", "is_trial": "yes", "is_duplicate": true}, "duplicate", ""},
+		{"not assessed", map[string]interface{}{}, "", ""},
+	}
+
+	for _, test := range tests {
+
+		excluded := ""
+		if rule, ok := Excluded(Fields(test.record)); ok {
+			excluded = rule.Name
+		}
+		if excluded != test.excluded {
+			t.Errorf("%s: excluded by %q, expected %q", test.name, excluded, test.excluded)
+		}
+
+		included := ""
+		if rule, ok := Included(Fields(test.record)); ok {
+			included = rule.Name
+		}
+		if included != test.included {
+			t.Errorf("%s: included by %q, expected %q", test.name, included, test.included)
+		}
+	}
+}
+
+func TestRestored(t *testing.T) {
+
+	tests := []struct {
+		excluded string
+		included string
+		restored bool
+	}{
+		{"2020-04-10", "", false},
+		{"2020-04-10", "2020-06-01", true},
+		{"2020-04-10", "2020-04-10", true},
+		{"", "2020-06-01", true},
+		{"2020-07-01T10:00:00", "2020-06-01", false},
+	}
+
+	for _, test := range tests {
+		record := Fields(map[string]interface{}{
+			"exclusion_date": test.excluded, "inclusion_date": test.included})
+		if restored := Restored(record); restored != test.restored {
+			t.Errorf("Restored(%q, %q) = %v, expected %v", test.excluded, test.included, restored, test.restored)
+		}
+	}
+}
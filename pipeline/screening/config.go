@@ -0,0 +1,81 @@
+package screening
+
+// Version identifies the current definition of the keywords and thresholds,
+// it must be changed whenever the classification is modified
+const Version = "2020-07-20"
+
+// decisions suggested by the pre-screener
+const (
+	Include   = "include"
+	Exclude   = "automatic exclusion"
+	Uncertain = "unclear"
+)
+
+// thresholds of the scores to suggest a decision, records with scores between
+// the thresholds are suggested as unclear. exclusions are only suggested on
+// positive evidence of a design that is not interventional, the absence of
+// covid keywords is not sufficient (i.e. for unusual spellings)
+const (
+	covidInclude  = 2
+	designInclude = 1
+	designExclude = -2
+)
+
+// negations are words preceding a keyword that negate it (i.e.
+// "non-interventional", "not randomized"). text is compared after replacing
+// punctuation with spaces (see normalizeText)
+var negations = []string{"non", "not", "no", "without"}
+
+// covidKeywords are searched in the title, condition and summary of the
+// records to assess the relevance for covid-19. keywords are matched as whole
+// words, hyphens and spaces are equivalent (i.e. "sars-cov-2", "SARS CoV-2")
+var covidKeywords = []keyword{
+	{"covid", 2},
+	{"covid19", 2},
+	{"sars cov 2", 2},
+	{"sars cov2", 2},
+	{"sarscov2", 2},
+	{"2019 ncov", 2},
+	{"2019 novel coronavirus", 2},
+	{"coronavirus disease 2019", 2},
+	{"novel coronavirus", 1},
+	{"coronavirus", 1},
+	{"ncov", 1},
+	{"pandemic", 1},
+}
+
+// interventionalKeywords are searched in the study type, title and summary of
+// the records to assess an interventional design
+var interventionalKeywords = []keyword{
+	{"interventional", 2},
+	{"randomized", 1},
+	{"randomised", 1},
+	{"placebo", 1},
+	{"controlled trial", 1},
+	{"clinical trial", 1},
+	{"phase 1", 1},
+	{"phase 2", 1},
+	{"phase 3", 1},
+	{"phase 4", 1},
+	{"phase i", 1},
+	{"phase ii", 1},
+	{"phase iii", 1},
+	{"phase iv", 1},
+	{"efficacy", 1},
+}
+
+// observationalKeywords are searched in the study type, title and summary of
+// the records and indicate a design that is not interventional
+var observationalKeywords = []keyword{
+	{"observational", -2},
+	{"non interventional", -2},
+	{"cohort", -1},
+	{"registry", -1},
+	{"case series", -1},
+	{"case report", -1},
+	{"retrospective", -1},
+	{"cross sectional", -1},
+	{"survey", -1},
+	{"prevalence", -1},
+	{"diagnostic accuracy", -1},
+}
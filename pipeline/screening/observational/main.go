@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"dkfbasel.ch/covid-evidence/ninox"
+	"dkfbasel.ch/covid-evidence/review"
+)
+
+// actions reported for the observational records
+const (
+	actionRemove    = "remove from covebasic"
+	actionScreening = "exclude in screening"
+	actionReviewed  = "kept (reviewed)"
+	actionExcluded  = "kept (in exclusions)"
+	actionNone      = "nothing to do"
+)
+
+// reconciliation contains the changes for a single observational record
+type reconciliation struct {
+	source    ninox.Source
+	sourceID  string
+	action    string
+	screening *ninox.Record
+	update    *ninox.Record
+	basic     *ninox.Record
+}
+
+// main will reconcile observational studies in all screening tables with
+// covebasic: the screening decision is set to "automatic exclusion" and
+// records that were not reviewed yet (review status "prefilled automatically")
+// are removed from covebasic. the screening update and the removal of each
+// record are applied together. the sources can be restricted by passing their
+// names as arguments
+func main() {
+
+	sources := []ninox.Source{}
+	for _, source := range ninox.Sources {
+		// only sources with study type can be reconciled
+		if _, ok := source.Fields["study_type"]; ok {
+			sources = append(sources, source)
+		}
+	}
+
+	if len(os.Args) > 1 {
+		sources = []ninox.Source{}
+		for _, name := range os.Args[1:] {
+			source, ok := ninox.SourceByName(name)
+			if !ok {
+				log.Fatalf("unknown source: %s", name)
+			}
+			if _, ok := source.Fields["study_type"]; !ok {
+				log.Fatalf("source does not provide a study type: %s", source.Name)
+			}
+			sources = append(sources, source)
+		}
+	}
+
+	// fetch all items from covebasic and the exclusions
+	basicRecords, basicIndex, err := ninox.FetchCoveBasic()
+	if err != nil {
+		log.Fatalf("could not fetch covebasic records: %+v", err)
+	}
+	log.Printf("fetched %d basic records", len(basicRecords))
+
+	list := []reconciliation{}
+	actionCounter := make(map[string]int)
+
+	for _, source := range sources {
+
+		screeningRecords, err := ninox.FetchRecords(source.URL, "")
+		if err != nil {
+			log.Fatalf("could not fetch screening records of %s: %+v", source.Name, err)
+		}
+		log.Printf("fetched %d screening records of %s", len(screeningRecords), source.Name)
+
+		for i := range screeningRecords {
+			s := &screeningRecords[i]
+
+			studyType := strings.ToLower(source.Field(s, "study_type"))
+			if !strings.Contains(studyType, "observational") {
+				continue
+			}
+
+			item := reconcile(source, s, basicIndex)
+			actionCounter[item.action]++
+
+			if item.action != actionNone {
+				fmt.Printf("%-22s %s: %s\n", item.action+":", source.Name, item.sourceID)
+			}
+
+			if item.update != nil || item.basic != nil {
+				list = append(list, item)
+			}
+		}
+	}
+
+	for _, action := range []string{actionRemove, actionScreening, actionReviewed, actionExcluded, actionNone} {
+		log.Printf("%-22s %03d", action+":", actionCounter[action])
+	}
+
+	if len(list) == 0 {
+		return
+	}
+
+	var action string
+	fmt.Printf("Perform operation [n]: ")
+	fmt.Scanln(&action) // nolint:errcheck
+
+	if action != "y" && action != "yes" {
+		return
+	}
+
+	// apply the screening update and the removal of each record together
+	failed := 0
+	for _, item := range list {
+
+		tx := ninox.NewTransaction()
+		if item.update != nil {
+			tx.Update(item.source.URL, item.update, item.screening)
+		}
+		if item.basic != nil {
+			tx.Delete(ninox.CoveBasicURL, item.basic)
+		}
+
+		err := tx.Commit()
+		if err != nil {
+			log.Printf("could not reconcile %s: %s: %+v", item.source.Name, item.sourceID, err)
+			failed++
+		}
+	}
+
+	log.Printf("reconciled %d records, %d failed", len(list)-failed, failed)
+}
+
+// reconcile will determine the changes for the given observational screening
+// record
+func reconcile(source ninox.Source, s *ninox.Record, basicIndex *ninox.Index) reconciliation {
+
+	item := reconciliation{
+		source:    source,
+		sourceID:  s.Field(source.IDField),
+		screening: s,
+		action:    actionNone,
+	}
+
+	// the screening decision is only set if not set to exclusion already
+	screening := s.Field("cove_screening")
+	excludeScreening := func() {
+		if screening == "automatic exclusion" {
+			return
+		}
+		update := ninox.Record{ID: s.ID, Fields: make(map[string]interface{})}
+		update.Fields["cove_screening"] = "automatic exclusion"
+		item.update = &update
+	}
+
+	info, ok := basicIndex.GetKey(source.Name, item.sourceID)
+
+	switch {
+	case !ok:
+		// records that were not transferred to covebasic are only excluded
+		// in the screening if no decision was taken yet
+		if screening == "" {
+			excludeScreening()
+		}
+
+	case info.Table == ninox.CoveBasicExlusionsTable:
+		item.action = actionExcluded
+		if screening == "" {
+			excludeScreening()
+		}
+		return item
+
+	case !review.Automated(info.Record.Field("review_status")):
+		// records reviewed by a human are kept
+		item.action = actionReviewed
+		return item
+
+	default:
+		item.basic = info.Record
+		excludeScreening()
+		item.action = actionRemove
+		return item
+	}
+
+	if item.update != nil {
+		item.action = actionScreening
+	}
+
+	return item
+}
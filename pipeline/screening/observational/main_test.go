@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+func TestReconcile(t *testing.T) {
+
+	source, _ := ninox.SourceByName("clinicaltrials.gov")
+
+	basic := func(id int, sourceID string, reviewStatus string) *ninox.Record {
+		return &ninox.Record{ID: id, Fields: map[string]interface{}{
+			"source":        "clinicaltrials.gov",
+			"source_id":     sourceID,
+			"review_status": reviewStatus,
+		}}
+	}
+
+	index := ninox.NewIndex()
+	index.Add(basic(1, "NCT00000002", ""), ninox.CoveBasicExlusionsTable)
+	index.Add(basic(2, "NCT00000003", "in extraction"), ninox.CoveBasicTable)
+	index.Add(basic(3, "NCT00000004", "prefilled automatically"), ninox.CoveBasicTable)
+	index.Add(basic(4, "NCT00000005", "prefilled automatically"), ninox.CoveBasicTable)
+
+	tests := []struct {
+		name      string
+		nctID     string
+		screening string
+		action    string
+		update    bool
+		basic     int
+	}{
+		{"not transferred", "NCT00000001", "", actionScreening, true, 0},
+		{"not transferred, decision taken", "NCT00000001", "1", actionNone, false, 0},
+		{"in exclusions", "NCT00000002", "", actionExcluded, true, 0},
+		{"in exclusions, decision taken", "NCT00000002", "include", actionExcluded, false, 0},
+		{"reviewed", "NCT00000003", "include", actionReviewed, false, 0},
+		{"prefilled", "NCT00000004", "include", actionRemove, true, 3},
+		{"prefilled, excluded in screening", "NCT00000005", "automatic exclusion", actionRemove, false, 4},
+	}
+
+	for _, test := range tests {
+
+		s := &ninox.Record{ID: 10, Fields: map[string]interface{}{
+			"nct_id":         test.nctID,
+			"study_type":     "Observational",
+			"cove_screening": test.screening,
+		}}
+
+		item := reconcile(source, s, index)
+
+		if item.action != test.action {
+			t.Errorf("%s: action %q, expected %q", test.name, item.action, test.action)
+		}
+
+		if (item.update != nil) != test.update {
+			t.Errorf("%s: screening update %v, expected %v", test.name, item.update != nil, test.update)
+		} else if item.update != nil && (item.update.ID != 10 || item.update.Field("cove_screening") != "automatic exclusion") {
+			t.Errorf("%s: unexpected screening update %+v", test.name, *item.update)
+		}
+
+		basicID := 0
+		if item.basic != nil {
+			basicID = item.basic.ID
+		}
+		if basicID != test.basic {
+			t.Errorf("%s: removes covebasic record %d, expected %d", test.name, basicID, test.basic)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"dkfbasel.ch/covid-evidence/helpers"
+	"dkfbasel.ch/covid-evidence/ninox"
+	"dkfbasel.ch/covid-evidence/screening"
+)
+
+// main will suggest a screening decision for all screening records without
+// decision. the suggestion and its explanation are stored with certainty
+// "generated" and must be confirmed by setting cove_screening manually.
+// the sources can be restricted by passing their names as arguments
+func main() {
+
+	sources := ninox.Sources
+	if len(os.Args) > 1 {
+		sources = []ninox.Source{}
+		for _, name := range os.Args[1:] {
+			source, ok := ninox.SourceByName(name)
+			if !ok {
+				log.Fatalf("unknown source: %s", name)
+			}
+			sources = append(sources, source)
+		}
+	}
+
+	updates := make(map[string][]*ninox.Record)
+	total := 0
+
+	for _, source := range sources {
+
+		log.Printf("fetching screening records of %s", source.Name)
+
+		records, err := ninox.FetchRecords(source.URL, "")
+		if err != nil {
+			log.Fatalf("could not fetch screening records of %s: %+v", source.Name, err)
+		}
+
+		decisions := make(map[string]int)
+
+		for i := range records {
+			s := &records[i]
+
+			// only records without screening decision are classified
+			if s.Field("cove_screening") != "" {
+				continue
+			}
+
+			suggestion := screening.Classify(screening.Input{
+				Title:     source.Field(s, "title"),
+				Condition: source.Field(s, "condition"),
+				StudyType: source.Field(s, "study_type"),
+				Summary:   source.Field(s, "summary"),
+			})
+			decisions[suggestion.Decision]++
+
+			// initialize the update with the current suggestion to only update
+			// changed values (suggestions confirmed by a human are kept)
+			r := ninox.Record{}
+			r.ID = s.ID
+			r.Fields = make(map[string]interface{})
+			for _, field := range []string{"cove_screening_suggestion", "cove_screening_explanation"} {
+				r.Fields[field] = s.Field(field)
+				r.Fields[field+"_certainty"] = s.Field(field + "_certainty")
+			}
+
+			r.Update("cove_screening_suggestion", suggestion.Decision, helpers.AsGenerated)
+			r.Update("cove_screening_explanation", suggestion.Explanation(), helpers.AsGenerated)
+
+			// nothing to do, if the record was not changed
+			if r.IsUpdated == false {
+				continue
+			}
+
+			updates[source.Name] = append(updates[source.Name], &r)
+		}
+
+		log.Printf("%s: %d records to screen (include: %d, exclude: %d, unclear: %d), %d to update",
+			source.Name, decisions[screening.Include]+decisions[screening.Exclude]+decisions[screening.Uncertain],
+			decisions[screening.Include], decisions[screening.Exclude], decisions[screening.Uncertain],
+			len(updates[source.Name]))
+
+		total += len(updates[source.Name])
+	}
+
+	if total == 0 {
+		fmt.Println("no suggestions to update")
+		return
+	}
+
+	var action string
+	fmt.Printf("Perform operation [n]: ")
+	fmt.Scanln(&action) // nolint:errcheck
+
+	if action != "y" && action != "yes" {
+		return
+	}
+
+	for _, source := range sources {
+		if len(updates[source.Name]) == 0 {
+			continue
+		}
+
+		err := ninox.UpdateRecords(source.URL, updates[source.Name])
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+	}
+}
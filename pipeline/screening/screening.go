@@ -0,0 +1,169 @@
+package screening
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"dkfbasel.ch/covid-evidence/helpers"
+)
+
+// Input contains the fields of a screening record that are used to suggest a
+// screening decision, the fields are mapped per source (see ninox.Source)
+type Input struct {
+	Title     string
+	Condition string
+	StudyType string
+	Summary   string
+}
+
+// Suggestion is the suggested screening decision with the scores and the
+// explanation of the signals found in the record
+type Suggestion struct {
+	Decision    string
+	CovidScore  int
+	DesignScore int
+	Signals     []string
+}
+
+// Explanation will return the signals that lead to the suggestion
+func (s Suggestion) Explanation() string {
+	signals := "no signals found"
+	if len(s.Signals) > 0 {
+		signals = strings.Join(s.Signals, "; ")
+	}
+	return fmt.Sprintf("covid: %+d, design: %+d (%s) [v%s]",
+		s.CovidScore, s.DesignScore, signals, Version)
+}
+
+// keyword is a search term with the weight added to the score if found
+type keyword struct {
+	term   string
+	weight int
+}
+
+// Classify will score the record on the relevance for covid-19 and on the
+// interventional design and suggest a screening decision
+func Classify(input Input) Suggestion {
+
+	s := Suggestion{}
+
+	// records without any information cannot be classified
+	if strings.TrimSpace(input.Title+input.Condition+input.StudyType+input.Summary) == "" {
+		s.Decision = Uncertain
+		return s
+	}
+
+	// relevance for covid-19 from the title, condition and summary, every
+	// keyword is only counted once per field
+	for _, field := range []struct{ name, value string }{
+		{"title", input.Title},
+		{"condition", input.Condition},
+		{"summary", input.Summary},
+	} {
+		s.CovidScore += s.score("covid", field.name, field.value, covidKeywords)
+	}
+
+	// the design is taken from the study type if available, the title and
+	// summary are only used otherwise
+	if strings.TrimSpace(input.StudyType) != "" {
+		s.DesignScore += s.score("design", "study type", input.StudyType, interventionalKeywords)
+		s.DesignScore += s.score("design", "study type", input.StudyType, observationalKeywords)
+	} else {
+		for _, field := range []struct{ name, value string }{
+			{"title", input.Title},
+			{"summary", input.Summary},
+		} {
+			s.DesignScore += s.score("design", field.name, field.value, interventionalKeywords)
+			s.DesignScore += s.score("design", field.name, field.value, observationalKeywords)
+		}
+	}
+
+	switch {
+	case s.DesignScore <= designExclude:
+		s.Decision = Exclude
+	case s.CovidScore >= covidInclude && s.DesignScore >= designInclude:
+		s.Decision = Include
+	default:
+		s.Decision = Uncertain
+	}
+
+	return s
+}
+
+// score will search the keywords as whole words in the given value and record
+// the signals found. negated keywords are ignored (i.e. "non-interventional")
+// and only the most specific keyword is counted if keywords overlap (i.e.
+// "novel coronavirus" and "coronavirus")
+func (s *Suggestion) score(category string, field string, value string, keywords []keyword) int {
+
+	value = normalizeText(value)
+	if value == "" {
+		return 0
+	}
+
+	total := 0
+	found := []string{}
+
+	for _, k := range keywords {
+
+		term := normalizeText(k.term)
+		if !containsKeyword(value, term) {
+			continue
+		}
+
+		// skip keywords that are part of a keyword found before
+		overlapping := false
+		for _, f := range found {
+			if helpers.ContainsWord(f, term) {
+				overlapping = true
+				break
+			}
+		}
+		if overlapping {
+			continue
+		}
+
+		found = append(found, term)
+		total += k.weight
+		s.Signals = append(s.Signals,
+			fmt.Sprintf("%s: %s contains %q (%+d)", category, field, k.term, k.weight))
+	}
+
+	return total
+}
+
+// containsKeyword will check if the text contains the term as whole word,
+// which is not preceded by a negation
+func containsKeyword(text string, term string) bool {
+
+	for _, i := range helpers.IndexWords(text, term) {
+
+		before := strings.Fields(text[:i])
+		if len(before) > 0 && isNegation(before[len(before)-1]) {
+			continue
+		}
+		return true
+	}
+
+	return false
+}
+
+// isNegation will check if the given word negates the following word
+func isNegation(word string) bool {
+	for _, negation := range negations {
+		if word == negation {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeText will convert the text to lowercase and replace all characters
+// that are not letters or digits with a single space, so that spellings with
+// hyphens and spaces are matched equally (i.e. "SARS-CoV-2" and "SARS CoV 2")
+func normalizeText(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
@@ -0,0 +1,76 @@
+package screening
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    Input
+		covid    int
+		design   int
+		decision string
+	}{
+		{"interventional covid trial",
+			Input{Title: "Hydroxychloroquine for COVID-19", StudyType: "Interventional"},
+			2, 2, Include},
+		{"space in sars cov 2",
+			Input{Title: "Treatment of SARS CoV-2 pneumonia", StudyType: "Interventional"},
+			2, 2, Include},
+		{"hyphenated sars-cov-2",
+			Input{Condition: "SARS-CoV-2 Infection", StudyType: "Interventional"},
+			2, 2, Include},
+		{"non-interventional study type",
+			Input{Title: "COVID-19 registry", StudyType: "Non-interventional"},
+			2, -2, Exclude},
+		{"observational study type",
+			Input{Title: "Outcomes of COVID-19", StudyType: "Observational [Patient Registry]"},
+			2, -3, Exclude},
+		{"phase of the disease",
+			Input{Title: "Immune response in the acute phase of COVID-19"},
+			2, 0, Uncertain},
+		{"phase of the trial",
+			Input{Title: "A phase 2 trial of remdesivir in COVID-19"},
+			2, 1, Include},
+		{"no covid keyword",
+			Input{Title: "Vitamin D in influenza", StudyType: "Interventional"},
+			0, 2, Uncertain},
+		{"negated covid keyword",
+			Input{Title: "Elective surgery in non-COVID patients", StudyType: "Interventional"},
+			0, 2, Uncertain},
+		{"negated randomization",
+			Input{Title: "Convalescent plasma for COVID-19", Summary: "a not randomized study"},
+			2, 0, Uncertain},
+		{"overlapping keywords",
+			Input{Title: "2019-nCoV pneumonia", StudyType: "Interventional"},
+			2, 2, Include},
+		{"empty record", Input{}, 0, 0, Uncertain},
+	}
+
+	for _, test := range tests {
+		s := Classify(test.input)
+		if s.CovidScore != test.covid || s.DesignScore != test.design || s.Decision != test.decision {
+			t.Errorf("%s: covid %d, design %d, %s, expected %d, %d, %s (%s)", test.name,
+				s.CovidScore, s.DesignScore, s.Decision, test.covid, test.design, test.decision, s.Explanation())
+		}
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"SARS-CoV-2", "sars cov 2"},
+		{" SARS  CoV-2 ", "sars cov 2"},
+		{"Non-interventional (observational)", "non interventional observational"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if got := normalizeText(test.text); got != test.want {
+			t.Errorf("normalizeText(%q) = %q, expected %q", test.text, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package snapshots
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// categories of changes between two snapshots
+const (
+	CategoryNew        = "new trial"
+	CategoryRemoved    = "removed from registry"
+	CategoryWithdrawn  = "withdrawn"
+	CategoryStatus     = "status changed"
+	CategoryEnrollment = "enrollment changed"
+	CategoryResults    = "new results"
+	CategoryField      = "field changed"
+)
+
+// categoryOrder defines the order of the categories in the notes
+var categoryOrder = []string{
+	CategoryNew, CategoryRemoved, CategoryWithdrawn, CategoryStatus,
+	CategoryEnrollment, CategoryResults, CategoryField,
+}
+
+// Config defines the fields of the source with a special meaning
+type Config struct {
+	StatusField     string
+	EnrollmentField string
+	ResultsField    string
+
+	// fields that change with every update and are therefore not reported
+	Ignore []string
+}
+
+// Change describes a change of a single trial between two snapshots
+type Change struct {
+	ID       string
+	Category string
+	Field    string
+	Previous string
+	Current  string
+}
+
+// Diff will compare the records of two snapshots of the same source (indexed
+// by the id of the trial) and return all changes on field level
+func Diff(previous, current map[string]map[string]string, config Config) []Change {
+
+	changes := []Change{}
+
+	ignore := make(map[string]bool)
+	for _, field := range config.Ignore {
+		ignore[field] = true
+	}
+
+	for id, record := range current {
+
+		before, ok := previous[id]
+		if !ok {
+			changes = append(changes, Change{ID: id, Category: CategoryNew})
+			continue
+		}
+
+		// compare the fields in a stable order
+		fields := []string{}
+		for field := range record {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		for _, field := range fields {
+
+			if ignore[field] {
+				continue
+			}
+
+			// fields that are not contained in the previous snapshot (i.e. new
+			// columns in the export) can not be compared
+			previousValue, ok := before[field]
+			if !ok {
+				continue
+			}
+
+			value := record[field]
+			if strings.TrimSpace(value) == strings.TrimSpace(previousValue) {
+				continue
+			}
+
+			change := Change{
+				ID:       id,
+				Category: CategoryField,
+				Field:    field,
+				Previous: previousValue,
+				Current:  value,
+			}
+
+			switch field {
+			case config.StatusField:
+				change.Category = CategoryStatus
+				if strings.EqualFold(value, "withdrawn") {
+					change.Category = CategoryWithdrawn
+				}
+			case config.EnrollmentField:
+				change.Category = CategoryEnrollment
+			case config.ResultsField:
+				if previousValue == "" {
+					change.Category = CategoryResults
+				}
+			}
+
+			changes = append(changes, change)
+		}
+	}
+
+	for id := range previous {
+		if _, ok := current[id]; !ok {
+			changes = append(changes, Change{ID: id, Category: CategoryRemoved})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].ID != changes[j].ID {
+			return changes[i].ID < changes[j].ID
+		}
+		return changes[i].Field < changes[j].Field
+	})
+
+	return changes
+}
+
+// Summary will return a short description of the changes of each trial
+func Summary(changes []Change) map[string]string {
+
+	categories := make(map[string][]string)
+	for _, c := range changes {
+		list := categories[c.ID]
+		found := false
+		for _, category := range list {
+			if category == c.Category {
+				found = true
+			}
+		}
+		if !found {
+			categories[c.ID] = append(list, c.Category)
+		}
+	}
+
+	summary := make(map[string]string)
+	for id, list := range categories {
+		summary[id] = strings.Join(list, ", ")
+	}
+	return summary
+}
+
+// WriteChangelog will write all changes into a csv file
+func WriteChangelog(fileName string, source string, changes []Change) error {
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("could not create changelog: %w", err)
+	}
+	defer file.Close() // nolint:errcheck
+
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+
+	err = writer.Write([]string{"source", "source_id", "category", "field", "value_old", "value_new"})
+	if err != nil {
+		return fmt.Errorf("could not write changelog header: %w", err)
+	}
+
+	for _, c := range changes {
+		err = writer.Write([]string{source, c.ID, c.Category, c.Field, c.Previous, c.Current})
+		if err != nil {
+			return fmt.Errorf("could not write changelog: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteNotes will write a summary of the changes as markdown, i.e. to be used
+// for the weekly update notes
+func WriteNotes(fileName string, title string, changes []Change) error {
+
+	byCategory := make(map[string][]Change)
+	for _, c := range changes {
+		byCategory[c.Category] = append(byCategory[c.Category], c)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title)
+
+	for _, category := range categoryOrder {
+		list := byCategory[category]
+		if len(list) == 0 {
+			continue
+		}
+
+		// other field changes are only counted to keep the notes short
+		if category == CategoryField {
+			fmt.Fprintf(&b, "## Other changes\n\n%d field changes in %d trials\n\n",
+				len(list), len(Summary(list)))
+			continue
+		}
+
+		fmt.Fprintf(&b, "## %s (%d)\n\n", strings.ToUpper(category[:1])+category[1:], len(list))
+		for _, c := range list {
+			if c.Field == "" {
+				fmt.Fprintf(&b, "- %s\n", c.ID)
+				continue
+			}
+			fmt.Fprintf(&b, "- %s: %s → %s\n", c.ID, valueOrEmpty(c.Previous), valueOrEmpty(c.Current))
+		}
+		b.WriteString("\n")
+	}
+
+	err := ioutil.WriteFile(fileName, []byte(b.String()), 0644)
+	if err != nil {
+		return fmt.Errorf("could not write notes: %w", err)
+	}
+	return nil
+}
+
+// valueOrEmpty will return a placeholder for empty values
+func valueOrEmpty(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "[EMPTY]"
+	}
+	return value
+}
@@ -0,0 +1,53 @@
+package snapshots
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+
+	config := Config{
+		StatusField:     "status",
+		EnrollmentField: "enrollment",
+		ResultsField:    "results_first_posted",
+		Ignore:          []string{"last_update_posted"},
+	}
+
+	previous := map[string]map[string]string{
+		"NCT01": {"status": "Recruiting", "enrollment": "100", "results_first_posted": "", "last_update_posted": "2020-05-01"},
+		"NCT02": {"status": "Recruiting", "title": "Trial B"},
+		"NCT03": {"status": "Not yet recruiting", "title": "Trial C "},
+		"NCT04": {"status": "Recruiting"},
+	}
+
+	current := map[string]map[string]string{
+		"NCT01": {"status": "Completed", "enrollment": "120", "results_first_posted": "2020-07-01", "last_update_posted": "2020-07-01"},
+		"NCT02": {"status": "Withdrawn", "title": "Trial B (updated)", "phase": "Phase 3"},
+		"NCT03": {"status": "Not yet recruiting", "title": "Trial C"},
+		"NCT05": {"status": "Recruiting"},
+	}
+
+	expected := []Change{
+		{"NCT01", CategoryEnrollment, "enrollment", "100", "120"},
+		{"NCT01", CategoryResults, "results_first_posted", "", "2020-07-01"},
+		{"NCT01", CategoryStatus, "status", "Recruiting", "Completed"},
+		{"NCT02", CategoryWithdrawn, "status", "Recruiting", "Withdrawn"},
+		{"NCT02", CategoryField, "title", "Trial B", "Trial B (updated)"},
+		{"NCT04", CategoryRemoved, "", "", ""},
+		{"NCT05", CategoryNew, "", "", ""},
+	}
+
+	changes := Diff(previous, current, config)
+
+	if len(changes) != len(expected) {
+		t.Fatalf("Diff returned %d changes, expected %d: %+v", len(changes), len(expected), changes)
+	}
+	for i := range changes {
+		if changes[i] != expected[i] {
+			t.Errorf("change %d = %+v, expected %+v", i, changes[i], expected[i])
+		}
+	}
+
+	summary := Summary(changes)
+	if summary["NCT01"] != "enrollment changed, new results, status changed" || summary["NCT03"] != "" {
+		t.Errorf("Summary = %v", summary)
+	}
+}
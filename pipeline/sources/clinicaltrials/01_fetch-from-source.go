@@ -1,16 +1,37 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
+	"time"
+
+	"dkfbasel.ch/covid-evidence/cache"
+	"dkfbasel.ch/covid-evidence/manifest"
+	"dkfbasel.ch/covid-evidence/queries"
 )
 
+// pageSize is the maximum number of studies returned by clinicaltrials.gov
+const pageSize = 100
+
+// pageCache stores every fetched page on disk. identical requests within the
+// ttl are served from the cache, i.e. to rerun an interrupted fetch without
+// fetching all pages again. set CTGOV_OFFLINE=1 to replay cached responses
+// only (without any requests to clinicaltrials.gov)
+var pageCache = cache.New("./exports/cache", 24*time.Hour)
+
+func init() {
+	pageCache.Offline = os.Getenv("CTGOV_OFFLINE") == "1"
+}
+
 type fetchResponse struct {
 	FullStudiesResponse struct {
 		APIVrs           string
@@ -25,18 +46,37 @@ type fetchResponse struct {
 	}
 }
 
-// Fetch will fetch data from clinicaltrials.gov with the given query paramenter
-// and return the data as string matrix (i.e. for csv tables)
-func Fetch(query string, filename string) error {
+// Fetch will fetch data from clinicaltrials.gov with the given query and write
+// the studies as json lines (one study per line) to the export file. each page
+// is written as soon as it is fetched, so a partial fetch can be resumed by
+// calling fetch with the same query and filename again. the query used is
+// recorded in the manifest of the export (<filename>--fetch.manifest.json)
+func Fetch(query queries.Query, filename string) error {
+
+	manifestFile := manifest.Path(filename, "fetch")
+	filename = fmt.Sprintf("%s.jsonl", filename)
+
+	m, err := prepareManifest(manifestFile, query)
+	if err != nil {
+		return err
+	}
+
+	// continue after the studies that were fetched previously
+	fetched, err := prepareResume(filename)
+	if err != nil {
+		return fmt.Errorf("could not prepare export file: %w", err)
+	}
 
-	filename = fmt.Sprintf("%s.json", filename)
+	if fetched > 0 {
+		log.Printf("resume fetch after %d studies\n", fetched)
+	}
 
-	// open a file to save the results
-	output, err := os.Create(filename)
+	// open the file to append the results
+	output, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
 		return fmt.Errorf("could open file for export: %w", err)
 	}
-	defer output.Close()
+	defer output.Close() // nolint:errcheck
 
 	// prepare the api url
 	apiUrl, err := url.Parse("https://www.clinicaltrials.gov/api/query/full_studies")
@@ -46,16 +86,15 @@ func Fetch(query string, filename string) error {
 
 	// add additional query params
 	params := url.Values{}
-	params.Add("expr", query)
+	params.Add("expr", query.Expression)
 	params.Add("fmt", "JSON")
 
-	// save all studies exported from clinicaltrials.gov
-	studies := []json.RawMessage{}
-
-	// do not make more then 100 fetch attempts (just to ensure that we do not,
-	// overload the clinicaltrials.gov api if something goes wrong on our side)
-	currentFetchAttempt := 0
-	maxFetchAttempts := 100
+	// the number of requests is limited by the number of studies found (with
+	// one additional page for studies added during the fetch), to ensure that
+	// we do not overload the clinicaltrials.gov api if something goes wrong on
+	// our side. the limit is set with the first response
+	requests := 0
+	maxRequests := 0
 
 	// initialize variables to select the min and max ranking of the results
 	// (required for pagination of the clinicaltrials.gov results)
@@ -64,8 +103,8 @@ func Fetch(query string, filename string) error {
 	for {
 
 		// fetch the next batch of studies (maximum 100 per batch)
-		currentRank = currentFetchAttempt*100 + 1
-		maxRank = currentRank + 99
+		currentRank = fetched + 1
+		maxRank = currentRank + pageSize - 1
 		params.Set("min_rnk", strconv.Itoa(currentRank))
 		params.Set("max_rnk", strconv.Itoa(maxRank))
 
@@ -78,9 +117,33 @@ func Fetch(query string, filename string) error {
 			return fmt.Errorf("could not fetch data: %s, %w", apiUrl.String(), err)
 		}
 
-		// append the results to the previously fetched studies
+		// write the page to the export file, the studies are compacted to
+		// ensure that every study is written on a single line
+		var page bytes.Buffer
 		for _, study := range response.FullStudiesResponse.FullStudies {
-			studies = append(studies, study)
+			err = json.Compact(&page, study)
+			if err != nil {
+				return fmt.Errorf("could not compact study: %w", err)
+			}
+			page.WriteByte('\n')
+		}
+
+		_, err = output.Write(page.Bytes())
+		if err != nil {
+			return fmt.Errorf("could not write result to output: %w", err)
+		}
+
+		fetched += len(response.FullStudiesResponse.FullStudies)
+
+		// update the manifest after every page
+		m.APIVersion = response.FullStudiesResponse.APIVrs
+		m.DataVersion = response.FullStudiesResponse.DataVrs
+		m.Counts["studies_found"] = response.FullStudiesResponse.NStudiesFound
+		m.Counts["studies_fetched"] = fetched
+
+		err = m.Write(manifestFile)
+		if err != nil {
+			return err
 		}
 
 		// log some information
@@ -89,48 +152,123 @@ func Fetch(query string, filename string) error {
 			response.FullStudiesResponse.NStudiesFound,
 		)
 
-		// stop fetching if the rank is higher
-		if response.FullStudiesResponse.MaxRank >= response.FullStudiesResponse.NStudiesFound {
+		found := response.FullStudiesResponse.NStudiesFound
+
+		// stop fetching if all studies found were fetched
+		if fetched >= found || response.FullStudiesResponse.MaxRank >= found {
 			break
 		}
 
-		currentFetchAttempt++
-		if currentFetchAttempt > maxFetchAttempts {
-			break
+		// the export would be incomplete if no more studies are returned
+		if len(response.FullStudiesResponse.FullStudies) == 0 {
+			return fmt.Errorf("no studies returned after %d of %d studies", fetched, found)
+		}
+
+		requests++
+		if maxRequests == 0 {
+			maxRequests = (found-fetched)/pageSize + 2
+		}
+		if requests >= maxRequests {
+			return fmt.Errorf("fetch stopped after %d requests with %d of %d studies",
+				requests, fetched, found)
 		}
 
 	}
 
-	// convert the information to json
-	asJSON, err := json.Marshal(studies)
+	err = output.Close()
 	if err != nil {
-		return fmt.Errorf("could not marshal studies")
+		return fmt.Errorf("could not close export file: %w", err)
 	}
 
-	// write the information into the file
-	_, err = output.Write(asJSON)
+	// add the checksum of the complete export to the manifest
+	err = m.AddOutput(filename)
 	if err != nil {
-		return fmt.Errorf("could not write result to output")
+		return fmt.Errorf("could not add export to manifest: %w", err)
 	}
-	output.Close() // nolint:errcheck
 
-	return nil
+	return m.Write(manifestFile)
 }
 
-// performRequest will perform the request for clinicaltrials.gov and return
-// the parsed results
-func performRequest(url string) (*fetchResponse, error) {
+// prepareManifest will load the manifest of a previous (interrupted) fetch or
+// initialize the manifest for a new fetch. a fetch can only be resumed with
+// the same query
+func prepareManifest(fileName string, query queries.Query) (*manifest.Manifest, error) {
 
-	request, err := http.Get(url)
+	m := manifest.New("fetch", query.Source)
+	m.Query = query.ID()
+	m.Expression = query.Expression
+
+	if _, err := os.Stat(fileName); os.IsNotExist(err) {
+		return m, nil
+	}
+
+	previous, err := manifest.Load(fileName)
 	if err != nil {
-		return nil, fmt.Errorf("could not fetch data: %w", err)
+		return nil, err
+	}
+
+	if previous.Query != m.Query || previous.Expression != m.Expression {
+		return nil, fmt.Errorf("export was fetched with query %s, can not resume with %s",
+			previous.Query, m.Query)
 	}
 
-	content, err := ioutil.ReadAll(request.Body)
+	// keep the start of the previous fetch
+	m.Started = previous.Started
+	return m, nil
+}
+
+// prepareResume will count the complete studies in an existing export file
+// and remove an incomplete last line (i.e. if the process was interrupted
+// while writing)
+func prepareResume(filename string) (int, error) {
+
+	file, err := os.OpenFile(filename, os.O_RDWR, 0644)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("could not read response: %w", err)
+		return 0, fmt.Errorf("could not open export file: %w", err)
+	}
+	defer file.Close() // nolint:errcheck
+
+	reader := bufio.NewReader(file)
+
+	count := 0
+	var offset int64
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("could not read export file: %w", err)
+		}
+
+		if !json.Valid(line) {
+			break
+		}
+
+		count++
+		offset += int64(len(line))
+	}
+
+	err = file.Truncate(offset)
+	if err != nil {
+		return 0, fmt.Errorf("could not truncate export file: %w", err)
+	}
+
+	return count, nil
+}
+
+// performRequest will perform the request for clinicaltrials.gov and return
+// the parsed results
+func performRequest(url string) (*fetchResponse, error) {
+
+	content, err := fetchPage(url)
+	if err != nil {
+		return nil, err
 	}
-	request.Body.Close()
 
 	var response fetchResponse
 	err = json.Unmarshal(content, &response)
@@ -141,3 +279,29 @@ func performRequest(url string) (*fetchResponse, error) {
 	return &response, nil
 
 }
+
+// fetchPage will fetch the content of the given url from clinicaltrials.gov.
+// responses are served from the page cache if available
+func fetchPage(url string) ([]byte, error) {
+
+	return pageCache.Fetch(url, func() ([]byte, error) {
+
+		request, err := http.Get(url)
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch data: %w", err)
+		}
+		defer request.Body.Close() // nolint:errcheck
+
+		if request.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code: %d", request.StatusCode)
+		}
+
+		content, err := ioutil.ReadAll(request.Body)
+		if err != nil {
+			return nil, fmt.Errorf("could not read response: %w", err)
+		}
+
+		return content, nil
+	})
+
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"dkfbasel.ch/covid-evidence/cache"
+	"dkfbasel.ch/covid-evidence/queries"
+)
+
+// cachePage will store a page of the full studies api with the given number
+// of studies in the cache
+func cachePage(t *testing.T, c *cache.Cache, expression string, minRank int, studies int, found int) {
+
+	params := url.Values{}
+	params.Add("expr", expression)
+	params.Add("fmt", "JSON")
+	params.Set("min_rnk", strconv.Itoa(minRank))
+	params.Set("max_rnk", strconv.Itoa(minRank+pageSize-1))
+
+	var response fetchResponse
+	response.FullStudiesResponse.NStudiesFound = found
+	response.FullStudiesResponse.MinRank = minRank
+	response.FullStudiesResponse.MaxRank = minRank + studies - 1
+	response.FullStudiesResponse.NStudiesReturned = studies
+	response.FullStudiesResponse.FullStudies = []json.RawMessage{}
+	for i := 0; i < studies; i++ {
+		study := fmt.Sprintf(`{"Study": {"Rank": %d}}`, minRank+i)
+		response.FullStudiesResponse.FullStudies = append(response.FullStudiesResponse.FullStudies,
+			json.RawMessage(study))
+	}
+
+	content, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("could not marshal response: %v", err)
+	}
+
+	err = c.Put("https://www.clinicaltrials.gov/api/query/full_studies?"+params.Encode(), content)
+	if err != nil {
+		t.Fatalf("could not cache page: %v", err)
+	}
+}
+
+// countLines will return the number of lines of the given file
+func countLines(t *testing.T, path string) int {
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("could not open export: %v", err)
+	}
+	defer file.Close() // nolint:errcheck
+
+	count := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		count++
+	}
+	return count
+}
+
+func TestFetchOfflineReplay(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "ctgov")
+	if err != nil {
+		t.Fatalf("could not create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir) // nolint:errcheck
+
+	// replay the pages from a cache without any requests to clinicaltrials.gov
+	previous := pageCache
+	defer func() { pageCache = previous }()
+
+	pageCache = cache.New(filepath.Join(dir, "cache"), 0)
+	pageCache.Offline = true
+
+	query := queries.Query{Source: "clinicaltrials.gov", Name: "test", Version: 1, Expression: "covid"}
+
+	tests := []struct {
+		name    string
+		pages   []int // number of studies per page
+		found   int
+		studies int
+		fails   bool
+	}{
+		{"complete", []int{100, 50}, 150, 150, false},
+		{"single page", []int{20}, 20, 20, false},
+		{"no studies", []int{0}, 0, 0, false},
+		{"empty page before all studies", []int{100, 0}, 150, 100, true},
+		{"page not cached", []int{100}, 150, 100, true},
+	}
+
+	for i, test := range tests {
+
+		expression := fmt.Sprintf("%s %d", query.Expression, i)
+		for page, studies := range test.pages {
+			cachePage(t, pageCache, expression, page*pageSize+1, studies, test.found)
+		}
+
+		q := query
+		q.Expression = expression
+		export := filepath.Join(dir, fmt.Sprintf("export-%d", i))
+
+		err := Fetch(q, export)
+		if (err != nil) != test.fails {
+			t.Errorf("%s: Fetch returned %v", test.name, err)
+		}
+
+		if lines := countLines(t, export+".jsonl"); lines != test.studies {
+			t.Errorf("%s: export contains %d studies, expected %d", test.name, lines, test.studies)
+		}
+	}
+}
@@ -1,37 +1,32 @@
 package main
 
 import (
+	"bufio"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"os"
-	"strings"
 
-	"github.com/tidwall/gjson"
+	"dkfbasel.ch/covid-evidence/manifest"
 )
 
-// Parse will convert the given information to the specified data model
-func Parse(inputFile string) error {
-
-	jsonFileName := fmt.Sprintf("%s.json", inputFile)
+// parseBatchSize is the number of studies written before the csv is flushed
+const parseBatchSize = 100
 
-	content, err := ioutil.ReadFile(jsonFileName)
-	if err != nil {
-		return fmt.Errorf("could not open json file: %w", err)
-	}
+// Parse will convert the given information to the specified data model. the
+// studies are streamed from the export file, either from json lines or from
+// a json array (older exports), and written to the csv file in batches
+func Parse(inputFile string) error {
 
-	// parse the content of the json file
-	var studies []json.RawMessage
-	err = json.Unmarshal(content, &studies)
+	studies, closeInput, err := openStudies(inputFile)
 	if err != nil {
-		return fmt.Errorf("could not read file: %w", err)
+		return fmt.Errorf("could not open export: %w", err)
 	}
-
-	fmt.Println("number of studies", len(studies))
+	defer closeInput() // nolint:errcheck
 
 	// initialize a csv file for the output
-	csvFileName := strings.Replace(jsonFileName, ".json", ".csv", 1)
+	csvFileName := fmt.Sprintf("%s.csv", inputFile)
 	file, err := os.Create(csvFileName)
 	if err != nil {
 		return fmt.Errorf("could not create csv file: %w", err)
@@ -54,42 +49,25 @@ func Parse(inputFile string) error {
 	}
 
 	// iterate through all studies in the dataset
-	for _, study := range studies {
-
-		// initialize a searchable json structure
-		study := gjson.GetBytes(study, "Study")
+	count := 0
+	for {
+
+		// decode the next study into the typed model
+		var record studyRecord
+		err = studies(&record)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("could not decode study %d: %w", count+1, err)
+		}
 
 		// initialize a new row
 		row := make([]string, rowCount)
 
-		// try to extract the field content according to the field map
+		// extract the field content according to the field map
 		for i, field := range fieldMap {
-
-			// skip all fields without a search path specified
-			if field.Search == "" {
-				continue
-			}
-
-			value := study.Get(field.Search)
-			if !value.Exists() {
-				// fmt.Printf("could not find field: %s\n", field.Search)
-				continue
-			}
-
-			// concatenate arrays with semicolon
-			// note: might be better to keep items separated in the future
-			if value.IsArray() {
-				values := value.Array()
-				asStr := make([]string, len(values))
-				for j, item := range values {
-					asStr[j] = item.String()
-				}
-				row[i] = strings.Join(asStr, "; ")
-				continue
-			}
-
-			row[i] = value.String()
-
+			row[i] = field.Value(&record.Study)
 		}
 
 		// write the row to the csv file
@@ -97,11 +75,82 @@ func Parse(inputFile string) error {
 		if err != nil {
 			return fmt.Errorf("could not write study to csv output: %w", err)
 		}
-		writer.Flush()
+
+		// flush the csv writer after every batch
+		count++
+		if count%parseBatchSize == 0 {
+			writer.Flush()
+		}
+	}
+
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		return fmt.Errorf("could not write csv output: %w", err)
 	}
 
+	fmt.Println("number of studies", count)
+
 	file.Close() // nolint:errcheck
 
-	return nil
+	// write the manifest of the parsed export
+	m := manifest.New("parse", "clinicaltrials.gov")
+	m.Counts["studies"] = count
+
+	err = m.AddInput(manifest.Path(inputFile, "fetch"))
+	if err != nil {
+		return fmt.Errorf("could not add fetch manifest: %w", err)
+	}
+
+	err = m.AddOutput(csvFileName)
+	if err != nil {
+		return fmt.Errorf("could not add csv file to manifest: %w", err)
+	}
+
+	return m.Write(manifest.Path(inputFile, "parse"))
+
+}
+
+// openStudies will open the export with the given name and return a function
+// to decode the studies one by one. the function returns io.EOF after the
+// last study. exports in json lines format (.jsonl) are preferred
+func openStudies(inputFile string) (func(v interface{}) error, func() error, error) {
+
+	file, err := os.Open(fmt.Sprintf("%s.jsonl", inputFile))
+	if err == nil {
+		decoder := json.NewDecoder(bufio.NewReader(file))
+		return decoder.Decode, file.Close, nil
+	}
+
+	if !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("could not open json lines file: %w", err)
+	}
+
+	// older exports contain a single json array with all studies
+	file, err = os.Open(fmt.Sprintf("%s.json", inputFile))
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not open json file: %w", err)
+	}
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+
+	// read the opening bracket of the array
+	token, err := decoder.Token()
+	if err != nil {
+		file.Close() // nolint:errcheck
+		return nil, nil, fmt.Errorf("could not read file: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		file.Close() // nolint:errcheck
+		return nil, nil, fmt.Errorf("could not read file: expected json array")
+	}
+
+	next := func(v interface{}) error {
+		if !decoder.More() {
+			return io.EOF
+		}
+		return decoder.Decode(v)
+	}
 
+	return next, file.Close, nil
 }
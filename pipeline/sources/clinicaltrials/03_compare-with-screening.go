@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"dkfbasel.ch/covid-evidence/manifest"
 	"dkfbasel.ch/covid-evidence/ninox"
 )
 
@@ -38,19 +39,15 @@ func Compare(inputFile string) error {
 		return fmt.Errorf("could not fetch clnicaltrials records from ninox")
 	}
 
-	ninoxBasicIncluded, err := ninox.FetchRecords(ninox.CoveBasicURL, "")
+	// fetch all items from covebasic, indexed by clinicaltrials.gov
+	ninoxBasicIncluded, ninoxCoveBasicIndex, err := ninox.FetchCoveBasic("clinicaltrials.gov")
 	if err != nil {
 		return fmt.Errorf("could not fetch covebasic records from ninox")
 	}
 
-	ninoxBasicExcluded, err := ninox.FetchRecords(ninox.CoveBasicExlusionURL, "")
-	if err != nil {
-		return fmt.Errorf("could not fetch covebasic-exlude records from ninox")
-	}
-
 	fmt.Printf("from ninox, clinialtrials: %d\n", len(ninoxScreeningClinicaltrials))
 	fmt.Printf("from ninox, covebasic: %d\n", len(ninoxBasicIncluded))
-	fmt.Printf("from ninox, covebasic exlusion: %d\n", len(ninoxBasicExcluded))
+	fmt.Printf("from ninox, covebasic index: %d\n", ninoxCoveBasicIndex.Len())
 
 	// generate indices for all clinialtrials entries and all ninox entries
 	ninoxIndex := make(map[string]*ninox.Record)
@@ -59,26 +56,6 @@ func Compare(inputFile string) error {
 		ninoxIndex[id] = &ninoxScreeningClinicaltrials[i]
 	}
 
-	// index of items in covebasic or covebasicexclude
-	ninoxCoveBasicIndex := make(map[string]string)
-	for _, record := range ninoxBasicIncluded {
-		id := record.Field("source_id")
-		source := record.Field("source")
-		if source != "clinicaltrials.gov" {
-			continue
-		}
-		ninoxCoveBasicIndex[id] = "included"
-	}
-
-	for _, record := range ninoxBasicExcluded {
-		id := record.Field("source_id")
-		source := record.Field("source")
-		if source != "clinicaltrials.gov" {
-			continue
-		}
-		ninoxCoveBasicIndex[id] = "excluded"
-	}
-
 	// iterate through all items in the source and compare it with the ninox data
 	sourceIndex := make(map[string]*map[string]string)
 	actionCounter := make(map[string]int)
@@ -92,13 +69,8 @@ func Compare(inputFile string) error {
 		id := record["nct_id"]
 		sourceIndex[id] = &fromSource[i]
 
-		covebasic, ok := ninoxCoveBasicIndex[id]
-		if !ok {
-			covebasic = "not in covebasic"
-		}
-
 		// save current covebasic status
-		record["covebasic"] = covebasic
+		record["covebasic"] = covebasicStatus(ninoxCoveBasicIndex, id)
 
 		// skip all studies that are observational
 		if strings.Contains(strings.ToLower(record["study_type"]), "observational") {
@@ -111,7 +83,9 @@ func Compare(inputFile string) error {
 				actionCounter["skipped (observational)"]++
 
 			} else {
-				// study should be removed from ninox
+				// study should be removed from ninox, the study type is updated
+				// in the screening table by Import and the study is reconciled
+				// with covebasic by screening/observational
 				record["action"] = "remove from ninox (observational)"
 				actionCounter["remove from ninox (observational)"]++
 			}
@@ -228,6 +202,41 @@ func Compare(inputFile string) error {
 	writer.Flush()
 	file.Close()
 
-	return nil
+	// write the manifest of the comparison
+	m := manifest.New("compare", "clinicaltrials.gov")
+	m.Counts["studies"] = len(fromSource)
+	m.Counts["updates"] = len(updates)
+	for action, count := range actionCounter {
+		m.Counts[action] = count
+	}
+
+	err = m.AddInput(manifest.Path(inputFile, "parse"))
+	if err != nil {
+		return fmt.Errorf("could not add parse manifest: %w", err)
+	}
+
+	for _, output := range []string{"records", "updates"} {
+		err = m.AddOutput(fmt.Sprintf("%s--%s.csv", inputFile, output))
+		if err != nil {
+			return fmt.Errorf("could not add %s to manifest: %w", output, err)
+		}
+	}
 
+	return m.Write(manifest.Path(inputFile, "compare"))
+
+}
+
+// covebasicStatus will return the status of the study with the given id in
+// covebasic (inclusion takes precedence over exclusion, see ninox.Index)
+func covebasicStatus(index *ninox.Index, id string) string {
+
+	info, ok := index.Get(id)
+	if !ok {
+		return "not in covebasic"
+	}
+
+	if info.Table == ninox.CoveBasicExlusionsTable {
+		return "excluded"
+	}
+	return "included"
 }
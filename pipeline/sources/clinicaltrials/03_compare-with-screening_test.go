@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+func TestCovebasicStatus(t *testing.T) {
+
+	record := func(id int, sourceID string) *ninox.Record {
+		return &ninox.Record{ID: id, Fields: map[string]interface{}{
+			"source":    "clinicaltrials.gov",
+			"source_id": sourceID,
+		}}
+	}
+
+	index := ninox.NewIndex()
+	index.Add(record(1, "NCT04280705"), ninox.CoveBasicExlusionsTable)
+	index.Add(record(2, "NCT04280705"), ninox.CoveBasicTable)
+	index.Add(record(3, "NCT04315948"), ninox.CoveBasicExlusionsTable)
+	index.Add(record(4, "NCT04252274"), ninox.CoveBasicTable)
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		// the inclusion takes precedence over an older exclusion
+		{"NCT04280705", "included"},
+		{"NCT04315948", "excluded"},
+		{"NCT04252274", "included"},
+		{"NCT00000000", "not in covebasic"},
+	}
+
+	for _, test := range tests {
+		if got := covebasicStatus(index, test.id); got != test.want {
+			t.Errorf("covebasicStatus(%q) = %q, expected %q", test.id, got, test.want)
+		}
+	}
+}
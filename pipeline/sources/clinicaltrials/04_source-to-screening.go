@@ -6,12 +6,22 @@ import (
 	"strconv"
 	"time"
 
+	"dkfbasel.ch/covid-evidence/manifest"
 	"dkfbasel.ch/covid-evidence/ninox"
 )
 
 // Import will import new studies from clinicaltrials gov into the ninox database
+// and update the study type of screening records that became observational
 func Import(inputFile string) error {
 
+	// reference the manifest of the comparison in all imported records, to
+	// trace the values back to the export. exports compared before manifests
+	// were written can still be imported, export_manifest is left empty
+	exportReference, err := manifest.Reference(manifest.Path(inputFile, "compare"))
+	if err != nil {
+		log.Printf("warning: no manifest of the comparison, export_manifest is not set: %v", err)
+	}
+
 	inputFile = fmt.Sprintf("%s--records", inputFile)
 
 	// compile a list of all fields that should be checked in ninox
@@ -65,6 +75,7 @@ func Import(inputFile string) error {
 
 		record.Fields["cove_import_date"] = currentDate
 		record.Fields["cove_update_date"] = currentDate
+		record.Fields["export_manifest"] = exportReference
 
 		// add all fields to the record
 		for key, value := range row {
@@ -84,7 +95,16 @@ func Import(inputFile string) error {
 		toNinox = append(toNinox, &record)
 	}
 
+	// studies that became observational are reconciled from the study type in
+	// the screening table (see screening/observational)
+	screeningRecords, err := ninox.FetchRecords(ninox.ClinicaltrialsURL, "")
+	if err != nil {
+		return fmt.Errorf("could not fetch screening records: %w", err)
+	}
+	observational := observationalUpdates(fromSource, screeningRecords)
+
 	fmt.Printf("import records to ninox: %d\n", len(toNinox))
+	fmt.Printf("update study type to observational: %d\n", len(observational))
 
 	var confirm string
 	fmt.Print("Continue with import into screening [y/n]: ")
@@ -95,7 +115,43 @@ func Import(inputFile string) error {
 	}
 
 	// update the records in ninox
-	ninox.UpdateRecords(ninox.ClinicaltrialsURL, toNinox)
+	err = ninox.UpdateRecords(ninox.ClinicaltrialsURL, append(toNinox, observational...))
+	if err != nil {
+		return err
+	}
 
+	if len(observational) > 0 {
+		fmt.Println("run screening/observational to reconcile the observational studies with covebasic")
+	}
 	return nil
 }
+
+// observationalUpdates will return updates of the study type for all screening
+// records that are observational according to the source but not in ninox
+func observationalUpdates(fromSource []map[string]string, screeningRecords []ninox.Record) []*ninox.Record {
+
+	screeningIndex := make(map[string]*ninox.Record)
+	for i := range screeningRecords {
+		screeningIndex[screeningRecords[i].Field("nct_id")] = &screeningRecords[i]
+	}
+
+	updates := []*ninox.Record{}
+	for _, row := range fromSource {
+
+		if row["action"] != "remove from ninox (observational)" {
+			continue
+		}
+
+		s, ok := screeningIndex[row["nct_id"]]
+		if !ok || s.Field("study_type") == row["study_type"] {
+			continue
+		}
+
+		updates = append(updates, &ninox.Record{ID: s.ID, Fields: map[string]interface{}{
+			"study_type":       row["study_type"],
+			"cove_update_date": time.Now().Format("2006-01-02"),
+		}})
+	}
+
+	return updates
+}
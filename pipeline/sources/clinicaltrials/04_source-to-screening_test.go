@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+func TestObservationalUpdates(t *testing.T) {
+
+	fromSource := []map[string]string{
+		{"action": "remove from ninox (observational)", "nct_id": "NCT04280705", "study_type": "Observational"},
+		{"action": "remove from ninox (observational)", "nct_id": "NCT04315948", "study_type": "Observational"},
+		{"action": "skipped (observational)", "nct_id": "NCT04252274", "study_type": "Observational"},
+		{"action": "update in ninox", "nct_id": "NCT04321616", "study_type": "Observational"},
+	}
+
+	screeningRecords := []ninox.Record{
+		{ID: 1, Fields: map[string]interface{}{"nct_id": "NCT04280705", "study_type": "Interventional"}},
+		{ID: 2, Fields: map[string]interface{}{"nct_id": "NCT04315948", "study_type": "Observational"}},
+		{ID: 3, Fields: map[string]interface{}{"nct_id": "NCT04321616", "study_type": "Interventional"}},
+	}
+
+	updates := observationalUpdates(fromSource, screeningRecords)
+
+	if len(updates) != 1 {
+		t.Fatalf("observationalUpdates returned %d updates, expected 1", len(updates))
+	}
+	if updates[0].ID != 1 || updates[0].Field("study_type") != "Observational" {
+		t.Errorf("observationalUpdates updates record %d to %q, expected record 1 to %q",
+			updates[0].ID, updates[0].Field("study_type"), "Observational")
+	}
+}
@@ -5,10 +5,17 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"strconv"
 	"strings"
 
+	"dkfbasel.ch/covid-evidence/ages"
+	"dkfbasel.ch/covid-evidence/arms"
+	"dkfbasel.ch/covid-evidence/countries"
 	"dkfbasel.ch/covid-evidence/helpers"
+	"dkfbasel.ch/covid-evidence/interventions"
 	"dkfbasel.ch/covid-evidence/ninox"
+	"dkfbasel.ch/covid-evidence/registries"
+	"dkfbasel.ch/covid-evidence/review"
 )
 
 // ToCovebasic will transfer the records from the screening table to the covebasic table
@@ -30,7 +37,7 @@ func ToCovebasic() error {
 
 	log.Printf("fetched %d screening records", len(screeningRecords))
 	log.Printf("fetched %d basic records", len(covebasicRecords))
-	log.Printf("index contains %d records", len(covebasicIndex))
+	log.Printf("index contains %d records", covebasicIndex.Len())
 
 	// convert the export to our basic table
 	changes := convertRecords(
@@ -53,7 +60,10 @@ func ToCovebasic() error {
 	ioutil.WriteFile("output.json", payload, 0777)
 
 	// import the new basic records into ninox
-	ninox.UpdateRecords(ninox.CoveBasicURL, changes)
+	err = ninox.UpdateRecords(ninox.CoveBasicURL, changes)
+	if err != nil {
+		return fmt.Errorf("could not update records: %w", err)
+	}
 
 	log.Println("update of covebasic completed")
 
@@ -63,7 +73,7 @@ func ToCovebasic() error {
 
 // convertRecords will convert the clinicaltrials.gov records to covebasic
 func convertRecords(screeningRecords []ninox.Record, basicRecords []ninox.Record,
-	basicIndex ninox.Index) []*ninox.Record {
+	basicIndex *ninox.Index) []*ninox.Record {
 
 	sourceName := "clinicaltrials.gov"
 
@@ -93,13 +103,20 @@ func convertRecords(screeningRecords []ninox.Record, basicRecords []ninox.Record
 		r.Fields["source"] = sourceName
 		r.Fields["source_id"] = sourceID
 
-		r.Fields["review_status"] = "prefilled automatically"
+		r.Fields["review_status"] = string(review.Prefilled)
 		r.Fields["is_covid"] = "yes"
 		r.Fields["is_trial"] = "yes"
 		r.Fields["is_observational"] = "no"
 
 		r.Update("entry_type", "registration", nil)
 
+		// export_manifest, reference to the export the values originate from
+		r.Update("export_manifest", s.Field("export_manifest"), nil)
+
+		// registry_ids, all identifiers of the trial in any registry
+		r.Update("registry_ids", registries.List(sourceID, s.Field("org_study_id"),
+			s.Field("secondary_ids")), helpers.AsGenerated)
+
 		r.Update("url", s.Field("nct_id"), func(value string) (interface{}, bool) {
 			return fmt.Sprintf("https://clinicaltrials.gov/ct2/show/record/%s", value), true
 		})
@@ -128,27 +145,17 @@ func convertRecords(screeningRecords []ninox.Record, basicRecords []ninox.Record
 
 		r.Update("status", s.Fields["status"], helpers.ToLowerCase)
 
+		// country contains the country name or international if there are
+		// multiple countries, the countries field contains all iso codes and
+		// countries_unmatched the countries without iso code for review
 		r.Update("country", s.Fields["location_country"], func(country string) (interface{}, bool) {
-			// country field may contain multiple countries separated by semicolon
-			// -> use international if there are multiple countries
-			// -> use the country name if it is the same multiple times
-			if strings.Contains(country, ";") == false {
-				return country, false
-			}
-
-			items := strings.Split(country, "; ")
-			first := items[0]
-			international := false
-			for _, c := range items {
-				if c != first {
-					international = true
-				}
-			}
-			if international {
-				return "international", true
-			}
-
-			return first, true
+			return countries.Summary(country), true
+		})
+		r.Update("countries", s.Fields["location_country"], func(country string) (interface{}, bool) {
+			return countries.List(country), true
+		})
+		r.Update("countries_unmatched", s.Fields["location_country"], func(country string) (interface{}, bool) {
+			return countries.Unmatched(country), true
 		})
 
 		// randomization
@@ -177,11 +184,18 @@ func convertRecords(screeningRecords []ninox.Record, basicRecords []ninox.Record
 		// longitudinal structure
 		r.Update("longitudinal_structure", s.Fields["intervention_model"], helpers.ToLowerCase)
 
-		// n_arms, calculated by the number of arm types
-		r.Update("n_arms", s.Fields["arm_group_arm_group_type"], func(m string) (interface{}, bool) {
-			count := strings.Count(m, "; ") + 1
+		// n_arms and control_type, derived from the arm groups
+		armList := parseArms(s.Field("arm_groups"),
+			s.Field("arm_group_arm_group_label"), s.Field("arm_group_arm_group_type"))
+
+		r.Update("n_arms", len(armList), func(m string) (interface{}, bool) {
+			if m == "0" {
+				return "", true
+			}
+			count, _ := strconv.Atoi(m)
 			return count, true
 		})
+		r.Update("control_type", arms.ControlType(armList), helpers.AsGenerated)
 
 		// n_enrollment
 		r.Update("n_enrollment", s.Fields["enrollment"], helpers.ToInt)
@@ -192,48 +206,72 @@ func convertRecords(screeningRecords []ninox.Record, basicRecords []ninox.Record
 		// population_gender
 		r.Update("population_gender", s.Fields["gender"], helpers.ToLowerCase)
 
-		// skip population_age (difficult from min-max age)
-		// r.Update("population_age", "TODO", nil)
+		// population_age, derived from the minimum and maximum age, and the
+		// minimum and maximum age in years
+		r.Update("population_age", ages.Categories(s.Field("minimum_age"), s.Field("maximum_age")), helpers.AsGenerated)
+		r.Update("population_age_min", s.Field("minimum_age"), ages.Years)
+		r.Update("population_age_max", s.Field("maximum_age"), ages.Years)
 
-		// skip intervention_type
-		// r.Update("intervention_type"], "TODO", nil)
+		// intervention_type, intervention_name and control, the interventions
+		// are split into experimental interventions and controls using the arms
+		interventionList := parseInterventions(s.Field("interventions"),
+			s.Field("intervention_type"), s.Field("intervention_name"))
+		experimental, control := interventions.Split(interventionList, armList)
 
-		// skip intervention and control (are in the same field)
-		// r.Update("intervention_name"], "TODO", nil)
-		// r.Update("control"], "TODO", nil)
+		r.Update("intervention_type", interventions.Types(experimental), helpers.AsGenerated)
+		r.Update("intervention_name", interventions.Names(experimental), helpers.AsGenerated)
+		r.Update("control", interventions.Names(control), helpers.AsGenerated)
 
-		// out_primary_measure
-		r.Update("out_primary_measure", s.Fields["primary_outcome_measure"], nil)
+		// out_primary_measure, out_primary_desc and out_primary_timeframe
+		primaryOutcomes := parseOutcomes(s.Field("primary_outcomes"), s.Field("primary_outcome_measure"),
+			s.Field("primary_outcome_description"), s.Field("primary_outcome_time_frame"))
+		measure, description, timeFrame := flattenOutcomes(primaryOutcomes)
 
-		// out_primary_desc
-		r.Update("out_primary_desc", s.Fields["primary_outcome_description"], nil)
-
-		// out_primary_timeframe
-		r.Update("out_primary_timeframe", s.Fields["primary_outcome_time_frame"], nil)
+		r.Update("out_primary_measure", measure, nil)
+		r.Update("out_primary_desc", description, nil)
+		r.Update("out_primary_timeframe", timeFrame, nil)
 
 		r.Update("start_date", s.Fields["date_started"], helpers.ToIsoDate)
 		r.Update("end_date", s.Fields["date_completed"], helpers.ToIsoDate)
 
-		// skip results_available
-		// r.Update["results_available", "TODO", nil]
+		// results_available, if results are posted on clinicaltrials.gov or a
+		// publication of the results is referenced
+		resultsPublications := parseResultPublications(s.Field("references"))
+
+		resultsAvailable := "no"
+		if s.Field("results_section") == "yes" || len(resultsPublications) > 0 {
+			resultsAvailable = "yes"
+		}
+		r.Update("results_available", resultsAvailable, helpers.AsGenerated)
+
+		// results_date, date when the results were first posted
+		r.Update("results_date", s.Fields["date_results_first_posted"], helpers.ToIsoDate)
 
-		// skip results_expected
-		// r.Update["results_expected", "TODO", nil]
+		// results_publication, pubmed ids of the results publications
+		r.Update("results_publication", strings.Join(resultsPublications, "; "), nil)
+
+		// results_expected_date, results must be posted within 12 months
+		// of the primary completion date
+		completion := s.Field("date_primary_completed")
+		if completion == "" {
+			completion = s.Field("date_completed")
+		}
+		r.Update("results_expected_date", completion, helpers.ToResultsExpectedDate)
 
 		// ipd_sharing
 		r.Update("ipd_sharing", s.Fields["patient_data_sharing_ipd"], helpers.ToLowerCase)
 
 		// publication
-		r.Update("publication", s.Fields["publications_pmid"], nil)
-
-		// out_secondary_measure
-		r.Update("out_secondary_measure", s.Fields["secondary_outcome_measure"], nil)
+		r.Update("publication", s.Fields["publications_PMID"], nil)
 
-		// out_secondary_desc
-		r.Update("out_secondary_desc", s.Fields["secondary_outcome_description"], nil)
+		// out_secondary_measure, out_secondary_desc and out_secondary_timeframe
+		secondaryOutcomes := parseOutcomes(s.Field("secondary_outcomes"), s.Field("secondary_outcome_measure"),
+			s.Field("secondary_outcome_description"), s.Field("secondary_outcome_time_frame"))
+		measure, description, timeFrame = flattenOutcomes(secondaryOutcomes)
 
-		// out_secondary_timeframe
-		r.Update("out_secondary_timeframe", s.Fields["secondary_outcome_time_frame"], nil)
+		r.Update("out_secondary_measure", measure, nil)
+		r.Update("out_secondary_desc", description, nil)
+		r.Update("out_secondary_timeframe", timeFrame, nil)
 
 		// nothing to do, if the record was not changed
 		if r.IsUpdated == false {
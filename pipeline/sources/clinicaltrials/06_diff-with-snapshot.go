@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"dkfbasel.ch/covid-evidence/manifest"
+	"dkfbasel.ch/covid-evidence/ninox"
+	"dkfbasel.ch/covid-evidence/review"
+	"dkfbasel.ch/covid-evidence/snapshots"
+)
+
+// DiffSnapshots will compare two parsed exports of clinicaltrials.gov and
+// write a changelog of all changes made in the registry. records in covebasic
+// that were already extracted by humans are marked for re-review
+func DiffSnapshots(previousFile string, currentFile string) error {
+
+	previous, err := loadSnapshot(previousFile)
+	if err != nil {
+		return fmt.Errorf("could not load previous export: %w", err)
+	}
+
+	current, err := loadSnapshot(currentFile)
+	if err != nil {
+		return fmt.Errorf("could not load current export: %w", err)
+	}
+
+	changes := snapshots.Diff(previous, current, snapshots.Config{
+		StatusField:     "status",
+		EnrollmentField: "enrollment",
+		ResultsField:    "date_results_first_posted",
+		Ignore:          []string{"date_last_update_posted"},
+	})
+
+	fmt.Printf("changes between snapshots: %d\n", len(changes))
+
+	err = snapshots.WriteChangelog(fmt.Sprintf("%s--changes.csv", currentFile),
+		"clinicaltrials.gov", changes)
+	if err != nil {
+		return err
+	}
+
+	err = snapshots.WriteNotes(fmt.Sprintf("%s--changes.md", currentFile),
+		fmt.Sprintf("clinicaltrials.gov changes until %s", time.Now().Format("2006-01-02")),
+		changes)
+	if err != nil {
+		return err
+	}
+
+	// write the manifest of the changelog with references to both exports
+	m := manifest.New("diff", "clinicaltrials.gov")
+	m.Counts["changes"] = len(changes)
+	m.Counts["studies_changed"] = len(snapshots.Summary(changes))
+
+	for _, file := range []string{previousFile, currentFile} {
+		err = m.AddInput(manifest.Path(file, "parse"))
+		if err != nil {
+			return fmt.Errorf("could not add parse manifest: %w", err)
+		}
+	}
+
+	for _, output := range []string{"changes.csv", "changes.md"} {
+		err = m.AddOutput(fmt.Sprintf("%s--%s", currentFile, output))
+		if err != nil {
+			return fmt.Errorf("could not add %s to manifest: %w", output, err)
+		}
+	}
+
+	err = m.Write(manifest.Path(currentFile, "diff"))
+	if err != nil {
+		return err
+	}
+
+	// mark all records in covebasic that were already reviewed by humans,
+	// prefilled records are updated with the regular import
+	_, covebasicIndex, err := ninox.FetchCoveBasic("clinicaltrials.gov")
+	if err != nil {
+		return fmt.Errorf("could not fetch covebasic records from ninox: %w", err)
+	}
+
+	currentDate := time.Now().Format("2006-01-02")
+	updates := []*ninox.Record{}
+
+	for id, summary := range snapshots.Summary(changes) {
+
+		info, ok := covebasicIndex.Get(id)
+		if !ok || info.Table != ninox.CoveBasicTable {
+			continue
+		}
+
+		if review.Automated(info.Record.Field("review_status")) {
+			continue
+		}
+
+		r := ninox.Record{}
+		r.ID = info.ID
+		r.Fields = make(map[string]interface{})
+		r.Fields["registry_changes"] = summary
+		r.Fields["registry_change_date"] = currentDate
+		updates = append(updates, &r)
+	}
+
+	log.Printf("records to re-review in covebasic: %d", len(updates))
+
+	if len(updates) == 0 {
+		return nil
+	}
+
+	var confirm string
+	fmt.Print("Mark records for re-review in covebasic [y/n]: ")
+	fmt.Scanln(&confirm)
+	if confirm != "y" && confirm != "yes" {
+		log.Println("abort")
+		return nil
+	}
+
+	return ninox.UpdateRecords(ninox.CoveBasicURL, updates)
+}
+
+// loadSnapshot will load all fields with a ninox name from the given parsed
+// export indexed by the nct id. the columns are matched by the header, since
+// older exports may contain different columns
+func loadSnapshot(fileName string) (map[string]map[string]string, error) {
+
+	file, err := os.Open(fmt.Sprintf("%s.csv", fileName))
+	if err != nil {
+		return nil, fmt.Errorf("could not open input file: %w", err)
+	}
+	defer file.Close() // nolint:errcheck
+
+	reader := csv.NewReader(file)
+	reader.Comma = ';'
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read csv header: %w", err)
+	}
+
+	// match the columns with the ninox names of the field map
+	ninoxNames := make(map[string]string)
+	for _, field := range fieldMap {
+		if field.Ninox != "" {
+			ninoxNames[field.Name] = field.Ninox
+		}
+	}
+
+	records := make(map[string]map[string]string)
+
+	for {
+		row, err := reader.Read()
+
+		// stop when we reach the end of the record
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("could not read csv record: %w", err)
+		}
+
+		record := make(map[string]string)
+		for i, name := range header {
+			ninoxName, ok := ninoxNames[name]
+			if !ok || i >= len(row) {
+				continue
+			}
+			record[ninoxName] = row[i]
+		}
+
+		records[record["nct_id"]] = record
+	}
+
+	return records, nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dkfbasel.ch/covid-evidence/queries"
+)
+
+// studyFieldsPageSize is the maximum number of studies returned by the study
+// fields endpoint of clinicaltrials.gov
+const studyFieldsPageSize = 1000
+
+// studyFieldsResponse is the response of the study fields endpoint, every
+// field is returned as list of values
+type studyFieldsResponse struct {
+	StudyFieldsResponse struct {
+		NStudiesFound int
+		MinRank       int
+		MaxRank       int
+		StudyFields   []struct {
+			NCTId         []string
+			BriefTitle    []string
+			StudyType     []string
+			OverallStatus []string
+		}
+	}
+}
+
+// queryResult contains the information of a study found by a query
+type queryResult struct {
+	NCTId         string
+	BriefTitle    string
+	StudyType     string
+	OverallStatus string
+}
+
+// CompareQueries will fetch the result sets of both queries and write all
+// studies with the queries that found them to <filename>.csv, i.e. to
+// evaluate the effect of broadening the search
+func CompareQueries(a queries.Query, b queries.Query, filename string) error {
+
+	resultsA, err := fetchQueryResults(a)
+	if err != nil {
+		return fmt.Errorf("could not fetch results of %s: %w", a.ID(), err)
+	}
+
+	resultsB, err := fetchQueryResults(b)
+	if err != nil {
+		return fmt.Errorf("could not fetch results of %s: %w", b.ID(), err)
+	}
+
+	// combine the results of both queries
+	all := make(map[string]queryResult)
+	for id, result := range resultsA {
+		all[id] = result
+	}
+	for id, result := range resultsB {
+		all[id] = result
+	}
+
+	ids := make([]string, 0, len(all))
+	for id := range all {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	file, err := os.Create(fmt.Sprintf("%s.csv", filename))
+	if err != nil {
+		return fmt.Errorf("could not create csv file: %w", err)
+	}
+	defer file.Close() // nolint:errcheck
+
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+
+	err = writer.Write([]string{"nct_id", "found_by", "brief_title", "study_type", "status"})
+	if err != nil {
+		return fmt.Errorf("could not write header to csv output: %w", err)
+	}
+
+	counts := make(map[string]int)
+
+	for _, id := range ids {
+
+		_, inA := resultsA[id]
+		_, inB := resultsB[id]
+
+		foundBy := "both"
+		if !inB {
+			foundBy = a.ID()
+		} else if !inA {
+			foundBy = b.ID()
+		}
+		counts[foundBy]++
+
+		result := all[id]
+		err = writer.Write([]string{id, foundBy, result.BriefTitle, result.StudyType, result.OverallStatus})
+		if err != nil {
+			return fmt.Errorf("could not write study to csv output: %w", err)
+		}
+	}
+
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		return fmt.Errorf("could not write csv output: %w", err)
+	}
+
+	log.Printf("%s: %d studies\n", a.ID(), len(resultsA))
+	log.Printf("%s: %d studies\n", b.ID(), len(resultsB))
+	log.Printf("found by both: %d, only %s: %d, only %s: %d\n",
+		counts["both"], a.ID(), counts[a.ID()], b.ID(), counts[b.ID()])
+
+	return nil
+}
+
+// fetchQueryResults will fetch the ids and some basic information of all
+// studies found with the given query
+func fetchQueryResults(query queries.Query) (map[string]queryResult, error) {
+
+	apiUrl, err := url.Parse("https://www.clinicaltrials.gov/api/query/study_fields")
+	if err != nil {
+		return nil, fmt.Errorf("could not parse url: %w", err)
+	}
+
+	params := url.Values{}
+	params.Add("expr", query.Expression)
+	params.Add("fields", "NCTId,BriefTitle,StudyType,OverallStatus")
+	params.Add("fmt", "JSON")
+
+	results := make(map[string]queryResult)
+
+	// the number of pages is limited by the number of studies found with the
+	// first response (with one additional page for studies added meanwhile)
+	maxPages := 1
+
+	for page := 0; ; page++ {
+
+		if page >= maxPages {
+			return nil, fmt.Errorf("fetch stopped after %d pages with %d studies", page, len(results))
+		}
+
+		minRank := page*studyFieldsPageSize + 1
+		params.Set("min_rnk", strconv.Itoa(minRank))
+		params.Set("max_rnk", strconv.Itoa(minRank+studyFieldsPageSize-1))
+		apiUrl.RawQuery = params.Encode()
+
+		content, err := fetchPage(apiUrl.String())
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch data: %s, %w", apiUrl.String(), err)
+		}
+
+		var response studyFieldsResponse
+		err = json.Unmarshal(content, &response)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse response: %w", err)
+		}
+
+		for _, study := range response.StudyFieldsResponse.StudyFields {
+			result := queryResult{
+				NCTId:         first(study.NCTId),
+				BriefTitle:    first(study.BriefTitle),
+				StudyType:     first(study.StudyType),
+				OverallStatus: first(study.OverallStatus),
+			}
+			results[result.NCTId] = result
+		}
+
+		found := response.StudyFieldsResponse.NStudiesFound
+		if page == 0 {
+			maxPages = found/studyFieldsPageSize + 2
+		}
+
+		if response.StudyFieldsResponse.MaxRank >= found {
+			break
+		}
+
+		if len(response.StudyFieldsResponse.StudyFields) == 0 {
+			return nil, fmt.Errorf("no studies returned after %d of %d studies", len(results), found)
+		}
+	}
+
+	return results, nil
+}
+
+// first will return the first value of the list or an empty string
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(values[0])
+}
@@ -1,6 +1,6 @@
 package main
 
-import "strings"
+import "dkfbasel.ch/covid-evidence/helpers"
 
 // isEqual will determine if to entries are equal
 func isEqual(value1, value2 string) (fullEqual, partialEqual bool) {
@@ -9,26 +9,10 @@ func isEqual(value1, value2 string) (fullEqual, partialEqual bool) {
 		fullEqual = true
 	}
 
-	if clean(value1) == clean(value2) {
+	if helpers.Clean(value1) == helpers.Clean(value2) {
 		partialEqual = true
 	}
 
 	return fullEqual, partialEqual
 
 }
-
-func clean(value string) string {
-
-	s := []byte(value)
-
-	j := 0
-	for _, b := range s {
-		if ('a' <= b && b <= 'z') ||
-			('A' <= b && b <= 'Z') ||
-			('0' <= b && b <= '9') {
-			s[j] = b
-			j++
-		}
-	}
-	return strings.ToLower(string(s[:j]))
-}
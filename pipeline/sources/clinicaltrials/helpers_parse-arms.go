@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"dkfbasel.ch/covid-evidence/arms"
+)
+
+// parseArms will parse the arm groups exported from clinicaltrials.gov. if
+// the structured arm groups are not available, the arms are derived from
+// the list of arm labels and types
+func parseArms(armGroups string, labels string, types string) []arms.Arm {
+
+	result := []arms.Arm{}
+
+	if strings.TrimSpace(armGroups) != "" {
+
+		var groups []armGroup
+		err := json.Unmarshal([]byte(armGroups), &groups)
+		if err == nil {
+			for _, g := range groups {
+				result = append(result, arms.Arm{
+					Label:         g.ArmGroupLabel,
+					Type:          g.ArmGroupType,
+					Description:   g.ArmGroupDescription,
+					Interventions: g.ArmGroupInterventionList.ArmGroupInterventionName,
+				})
+			}
+			return result
+		}
+	}
+
+	// note: labels may contain semicolons themselves, the types only use
+	// a fixed set of values and are therefore used for the count
+	if strings.TrimSpace(types) == "" {
+		return result
+	}
+
+	labelList := strings.Split(labels, "; ")
+	typeList := strings.Split(types, "; ")
+
+	for i, armType := range typeList {
+		arm := arms.Arm{Type: armType}
+		if len(labelList) == len(typeList) {
+			arm.Label = labelList[i]
+		}
+		result = append(result, arm)
+	}
+
+	return result
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"dkfbasel.ch/covid-evidence/interventions"
+)
+
+// parseInterventions will parse the interventions exported from
+// clinicaltrials.gov. if the structured interventions are not available, the
+// interventions are derived from the list of types and names
+func parseInterventions(list string, types string, names string) []interventions.Intervention {
+
+	result := []interventions.Intervention{}
+
+	if strings.TrimSpace(list) != "" {
+
+		var items []intervention
+		err := json.Unmarshal([]byte(list), &items)
+		if err == nil {
+			for _, item := range items {
+				result = append(result, interventions.Intervention{
+					Type:        item.InterventionType,
+					Name:        item.InterventionName,
+					Description: item.InterventionDescription,
+				})
+			}
+			return result
+		}
+	}
+
+	if strings.TrimSpace(names) == "" {
+		return result
+	}
+
+	typeList := strings.Split(types, "; ")
+	nameList := strings.Split(names, "; ")
+
+	for i, name := range nameList {
+		item := interventions.Intervention{Name: name}
+		if len(typeList) == len(nameList) {
+			item.Type = typeList[i]
+		}
+		result = append(result, item)
+	}
+
+	return result
+}
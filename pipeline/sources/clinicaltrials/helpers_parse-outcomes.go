@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// parseOutcomes will parse the outcomes exported from clinicaltrials.gov. if
+// the structured outcomes are not available, the outcomes are derived from
+// the lists of measures, descriptions and time frames (which are only aligned
+// if all lists contain the same number of items)
+func parseOutcomes(list string, measures string, descriptions string, timeFrames string) []outcome {
+
+	result := []outcome{}
+
+	if strings.TrimSpace(list) != "" {
+		err := json.Unmarshal([]byte(list), &result)
+		if err == nil {
+			return result
+		}
+	}
+
+	if strings.TrimSpace(measures) == "" {
+		return result
+	}
+
+	measureList := strings.Split(measures, "; ")
+	descriptionList := strings.Split(descriptions, "; ")
+	timeFrameList := strings.Split(timeFrames, "; ")
+
+	for i, measure := range measureList {
+		item := outcome{Measure: measure}
+		if len(descriptionList) == len(measureList) {
+			item.Description = descriptionList[i]
+		}
+		if len(timeFrameList) == len(measureList) {
+			item.TimeFrame = timeFrameList[i]
+		}
+		result = append(result, item)
+	}
+
+	return result
+}
+
+// flattenOutcomes will return the measures, descriptions and time frames of
+// the given outcomes. if there are multiple outcomes, all values are numbered
+// to keep the information of each outcome aligned
+func flattenOutcomes(outcomes []outcome) (string, string, string) {
+
+	if len(outcomes) == 1 {
+		return outcomes[0].Measure, outcomes[0].Description, outcomes[0].TimeFrame
+	}
+
+	measures := make([]string, len(outcomes))
+	descriptions := make([]string, len(outcomes))
+	timeFrames := make([]string, len(outcomes))
+
+	for i, o := range outcomes {
+		measures[i] = strings.TrimSpace(fmt.Sprintf("(%d) %s", i+1, o.Measure))
+		descriptions[i] = strings.TrimSpace(fmt.Sprintf("(%d) %s", i+1, o.Description))
+		timeFrames[i] = strings.TrimSpace(fmt.Sprintf("(%d) %s", i+1, o.TimeFrame))
+	}
+
+	return strings.Join(measures, "\n"), strings.Join(descriptions, "\n"), strings.Join(timeFrames, "\n")
+}
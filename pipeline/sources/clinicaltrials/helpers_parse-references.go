@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// parseResultPublications will return the pubmed ids of all references that
+// are marked as results publication in clinicaltrials.gov
+func parseResultPublications(references string) []string {
+
+	pmids := []string{}
+
+	if strings.TrimSpace(references) == "" {
+		return pmids
+	}
+
+	var items []reference
+	err := json.Unmarshal([]byte(references), &items)
+	if err != nil {
+		return pmids
+	}
+
+	for _, item := range items {
+		if strings.ToLower(item.ReferenceType) != "result" || item.ReferencePMID == "" {
+			continue
+		}
+		pmids = append(pmids, item.ReferencePMID)
+	}
+
+	return pmids
+}
@@ -6,13 +6,18 @@ import (
 
 func main() {
 
-	// query := `(wuhan AND (coronavirus OR corona virus OR pneumonia virus)) OR COVID19 OR COVID-19 OR COVID 19 OR coronavirus 2019 OR corona virus 2019 OR SARS-CoV-2 OR SARSCoV2 OR SARS2 OR SARS-2 OR 2019 nCoV OR ((novel coronavirus OR novel corona virus) AND 2019)`
+	// // the search queries are defined in queries/queries.json
+	// query, err := queries.Latest("clinicaltrials.gov", "covid")
+	// if err != nil {
+	// 	log.Fatalf("could not find query: %+v", err)
+	// }
 
 	// filename := fmt.Sprintf("./exports/clinicaltrials_%s", time.Now().Format("2006-01-02-150405"))
 	// fmt.Println("FILE:" filename)
 
-	// // fetch data from clinicaltrials.gov
-	// err := Fetch(query, filename)
+	// // fetch data from clinicaltrials.gov (to resume an interrupted fetch,
+	// // use the filename of the interrupted export)
+	// err = Fetch(query, filename)
 	// if err != nil {
 	// 	log.Fatalf("could not fetch data: %+v", err)
 	// }
@@ -40,6 +45,19 @@ func main() {
 
 	// ToCovebasic()
 
+	// // compare the export with the previous export to find registry changes
+	// err := DiffSnapshots("./exports/clinicaltrials_2020-06-12-061937", filename)
+	// if err != nil {
+	// 	log.Fatalf("could not compare snapshots: %+v", err)
+	// }
+
+	// // compare the result sets of two versions of the search query
+	// previousQuery, _ := queries.Get("clinicaltrials.gov", "covid", 1)
+	// err := CompareQueries(previousQuery, query, fmt.Sprintf("./exports/queries_%s", time.Now().Format("2006-01-02-150405")))
+	// if err != nil {
+	// 	log.Fatalf("could not compare queries: %+v", err)
+	// }
+
 	log.Println("finished")
 
 }
@@ -1,75 +1,221 @@
 package main
 
+// fieldMap defines the columns of the csv export and the corresponding field
+// in the ninox screening table. the value function flattens the information
+// of the typed study record, lists of objects are kept as json
 var fieldMap = []struct {
-	Ninox  string
-	Name   string
-	Search string
+	Ninox string
+	Name  string
+	Value func(s *study) string
 }{
-	{"nct_id", "NCTId", "ProtocolSection.IdentificationModule.NCTId"},
-	{"date_study_first_submitted", "StudyFirstSubmitDate", "ProtocolSection.StatusModule.StudyFirstSubmitDate"},
-	{"date_study_first_posted", "StudyFirstPostDate", "ProtocolSection.StatusModule.StudyFirstPostDateStruct.StudyFirstPostDate"},
-	{"", "StudyFirstPostDateType", "ProtocolSection.StatusModule.StudyFirstPostDateStruct.StudyFirstPostDateType"},
-	{"date_last_update_posted", "LastUpdatePostDate", "ProtocolSection.StatusModule.LastUpdatePostDateStruct.LastUpdatePostDate"},
-	{"", "LastUpdatePostDateType", "ProtocolSection.StatusModule.LastUpdatePostDateStruct.LastUpdatePostDateType"},
-	{"date_started", "StartDate", "ProtocolSection.StatusModule.StartDateStruct.StartDate"},
-	{"date_started_type", "StartDateType", "ProtocolSection.StatusModule.StartDateStruct.StartDateType"},
-	{"date_completed", "CompletionDate", "ProtocolSection.StatusModule.CompletionDateStruct.CompletionDate"},
-	{"date_completed_type", "CompletionDateType", "ProtocolSection.StatusModule.CompletionDateStruct.CompletionDateType"},
-	{"status", "OverallStatus", "ProtocolSection.StatusModule.OverallStatus"},
-	{"brief_title", "BriefTitle", "ProtocolSection.IdentificationModule.BriefTitle"},
-	{"official_title", "OfficialTitle", "ProtocolSection.IdentificationModule.OfficialTitle"},
-	{"brief_summary", "BriefSummary", "ProtocolSection.DescriptionModule.BriefSummary"},
-	{"detailed_description", "DetailedDescription", "ProtocolSection.DescriptionModule.DetailedDescription"},
-	{"study_type", "StudyType", "ProtocolSection.DesignModule.StudyType"},
-	{"phase", "Phase", "ProtocolSection.DesignModule.PhaseList.Phase"},
-	{"allocation", "DesignAllocation", "ProtocolSection.DesignModule.DesignInfo.DesignAllocation"},
-	{"intervention_model", "DesignInterventionModel", "ProtocolSection.DesignModule.DesignInfo.DesignInterventionModel"},
-	{"intervention_model_description", "DesignInterventionModelDescription", "ProtocolSection.DesignModule.DesignInfo.DesignInterventionModelDescription"},
-	{"primary_purpose", "DesignPrimaryPurpose", "ProtocolSection.DesignModule.DesignInfo.DesignPrimaryPurpose"},
-	{"masking", "DesignMasking", "ProtocolSection.DesignModule.DesignInfo.DesignMaskingInfo.DesignMasking"},
-	{"", "DesignMaskingDescription", "ProtocolSection.DesignModule.DesignInfo.DesignMaskingInfo.DesignMaskingDescription"},
-	{"", "DesignWhoMasked", "ProtocolSection.DesignModule.DesignInfo.DesignMaskingInfo.DesignWhoMaskedList.DesignWhoMasked"},
-	{"condition", "Condition", "ProtocolSection.ConditionsModule.ConditionList.Condition"},
-	{"", "Keyword", "ProtocolSection.ConditionsModule.KeywordList.Keyword"},
-	{"intervention_type", "InterventionType", "ProtocolSection.ArmsInterventionsModule.InterventionList.Intervention.#.InterventionType"},
-	{"intervention_name", "InterventionName", "ProtocolSection.ArmsInterventionsModule.InterventionList.Intervention.#.InterventionName"},
-	{"intervention_desc", "InterventionDescription", "ProtocolSection.ArmsInterventionsModule.InterventionList.Intervention.#.InterventionDescription"},
-	{"eligibility_criteria", "EligibilityCriteria", "ProtocolSection.EligibilityModule.EligibilityCriteria"},
-	{"gender", "Gender", "ProtocolSection.EligibilityModule.Gender"},
-	{"minimum_age", "MinimumAge", "ProtocolSection.EligibilityModule.MinimumAge"},
-	{"maximum_age", "MaximumAge", "ProtocolSection.EligibilityModule.MaximumAge"},
-	{"", "StdAge", "ProtocolSection.EligibilityModule.StdAgeList"},
-	{"healthy_volunteers", "HealthyVolunteers", "ProtocolSection.EligibilityModule.HealthyVolunteers"},
-	{"enrollment", "EnrollmentCount", "ProtocolSection.DesignModule.EnrollmentInfo.EnrollmentCount"},
-	{"enrollment_type", "EnrollmentType", "ProtocolSection.DesignModule.EnrollmentInfo.EnrollmentType"},
-	{"primary_outcome_measure", "PrimaryOutcomeMeasure", "ProtocolSection.OutcomesModule.PrimaryOutcomeList.PrimaryOutcome.#.PrimaryOutcomeMeasure"},
-	{"primary_outcome_description", "PrimaryOutcomeDescription", "ProtocolSection.OutcomesModule.PrimaryOutcomeList.PrimaryOutcome.#.PrimaryOutcomeDescription"},
-	{"primary_outcome_time_frame", "PrimaryOutcomeTimeFrame", "ProtocolSection.OutcomesModule.PrimaryOutcomeList.PrimaryOutcome.#.PrimaryOutcomeTimeFrame"},
-	{"secondary_outcome_measure", "SecondaryOutcomeMeasure", "ProtocolSection.OutcomesModule.SecondaryOutcomeList.SecondaryOutcome.#.SecondaryOutcomeMeasure"},
-	{"secondary_outcome_description", "SecondaryOutcomeDescription", "ProtocolSection.OutcomesModule.SecondaryOutcomeList.SecondaryOutcome.#.SecondaryOutcomeDescription"},
-	{"secondary_outcome_time_frame", "SecondaryOutcomeTimeFrame", "ProtocolSection.OutcomesModule.SecondaryOutcomeList.SecondaryOutcome.#.SecondaryOutcomeTimeFrame"},
-	{"arm_group_arm_group_label", "ArmGroupLabel", "ProtocolSection.ArmsInterventionsModule.ArmGroupList.ArmGroup.#.ArmGroupLabel"},
-	{"arm_group_arm_group_type", "ArmGroupType", "ProtocolSection.ArmsInterventionsModule.ArmGroupList.ArmGroup.#.ArmGroupType"},
-	{"arm_group_description", "ArmGroupDescription", "ProtocolSection.ArmsInterventionsModule.ArmGroupList.ArmGroup.#.ArmGroupDescription"},
-	{"location_name", "LocationFacility", "ProtocolSection.ContactsLocationsModule.LocationList.Location.#.LocationFacility"},
-	{"location_city", "LocationCity", "ProtocolSection.ContactsLocationsModule.LocationList.Location.#.LocationCity"},
-	{"location_country", "LocationCountry", "ProtocolSection.ContactsLocationsModule.LocationList.Location.#.LocationCountry"},
-	{"patient_data_sharing_ipd", "IPDSharing", "ProtocolSection.IPDSharingStatementModule.IPDSharing"},
-	{"sponsors_agency", "LeadSponsorName", "ProtocolSection.SponsorCollaboratorsModule.LeadSponsor.LeadSponsorName"},
-	{"sponsors_agency_class", "LeadSponsorClass", "ProtocolSection.SponsorCollaboratorsModule.LeadSponsor.LeadSponsorClass"},
-	{"publications_reference", "ReferenceCitation", "ProtocolSection.ReferencesModule.ReferenceList.Reference.#.ReferenceCitation"},
-	{"publications_PMID", "ReferencePMID", "ProtocolSection.ReferencesModule.ReferenceList.Reference.#.ReferencePMID"},
-	{"", "OverallOfficialName", "ProtocolSection.ContactsLocationsModule.OverallOfficialList.OverallOfficial.#.OverallOfficialName"},
-	{"", "OverallOfficialAffiliation", "ProtocolSection.ContactsLocationsModule.OverallOfficialList.OverallOfficial.#.OverallOfficialAffiliation"},
-	{"", "OverallOfficialRole", "ProtocolSection.ContactsLocationsModule.OverallOfficialList.OverallOfficial.#.OverallOfficialRole"},
-	{"", "CentralContactName", "ProtocolSection.ContactsLocationsModule.CentralContactList.CentralContact.#.CentralContactName"},
-	{"", "CentralContactRole", "ProtocolSection.ContactsLocationsModule.CentralContactList.CentralContact.#.CentralContactPhone"},
-	{"", "CentralContactPhone", "ProtocolSection.ContactsLocationsModule.CentralContactList.CentralContact.#.CentralContactRole"},
-	{"", "CentralContactPhoneExt", "ProtocolSection.ContactsLocationsModule.CentralContactList.CentralContact.#.CentralContactPhoneExt"},
-	{"", "CentralContactEMail", "ProtocolSection.ContactsLocationsModule.CentralContactList.CentralContact.#.CentralContactEMail"},
-	{"", "PointOfContactTitle", "ResultsSection.MoreInfoModule.PointOfContact.PointOfContactTitle"},
-	{"", "PointOfContactOrganization", "ResultsSection.MoreInfoModule.PointOfContact.PointOfContactOrganization"},
-	{"", "PointOfContactPhone", "ResultsSection.MoreInfoModule.PointOfContact.PointOfContactPhone"},
-	{"", "PointOfContactPhoneExt", "ResultsSection.MoreInfoModule.PointOfContact.PointOfContactPhoneExt"},
-	{"", "PointOfContactEMail", "ResultsSection.MoreInfoModule.PointOfContact.PointOfContactEMail"},
+	{"nct_id", "NCTId", func(s *study) string { return s.ProtocolSection.IdentificationModule.NCTId }},
+	{"org_study_id", "OrgStudyId", func(s *study) string { return s.ProtocolSection.IdentificationModule.OrgStudyIdInfo.OrgStudyId }},
+	{"secondary_ids", "SecondaryId", func(s *study) string {
+		list := s.ProtocolSection.IdentificationModule.SecondaryIdInfoList.SecondaryIdInfo
+		return each(len(list), func(i int) string { return list[i].SecondaryId })
+	}},
+	{"date_study_first_submitted", "StudyFirstSubmitDate", func(s *study) string { return s.ProtocolSection.StatusModule.StudyFirstSubmitDate }},
+	{"date_study_first_posted", "StudyFirstPostDate", func(s *study) string {
+		return s.ProtocolSection.StatusModule.StudyFirstPostDateStruct.StudyFirstPostDate
+	}},
+	{"", "StudyFirstPostDateType", func(s *study) string {
+		return s.ProtocolSection.StatusModule.StudyFirstPostDateStruct.StudyFirstPostDateType
+	}},
+	{"date_last_update_posted", "LastUpdatePostDate", func(s *study) string {
+		return s.ProtocolSection.StatusModule.LastUpdatePostDateStruct.LastUpdatePostDate
+	}},
+	{"", "LastUpdatePostDateType", func(s *study) string {
+		return s.ProtocolSection.StatusModule.LastUpdatePostDateStruct.LastUpdatePostDateType
+	}},
+	{"date_started", "StartDate", func(s *study) string { return s.ProtocolSection.StatusModule.StartDateStruct.StartDate }},
+	{"date_started_type", "StartDateType", func(s *study) string { return s.ProtocolSection.StatusModule.StartDateStruct.StartDateType }},
+	{"date_completed", "CompletionDate", func(s *study) string { return s.ProtocolSection.StatusModule.CompletionDateStruct.CompletionDate }},
+	{"date_completed_type", "CompletionDateType", func(s *study) string {
+		return s.ProtocolSection.StatusModule.CompletionDateStruct.CompletionDateType
+	}},
+	{"date_primary_completed", "PrimaryCompletionDate", func(s *study) string {
+		return s.ProtocolSection.StatusModule.PrimaryCompletionDateStruct.PrimaryCompletionDate
+	}},
+	{"date_primary_completed_type", "PrimaryCompletionDateType", func(s *study) string {
+		return s.ProtocolSection.StatusModule.PrimaryCompletionDateStruct.PrimaryCompletionDateType
+	}},
+	{"date_results_first_posted", "ResultsFirstPostDate", func(s *study) string {
+		return s.ProtocolSection.StatusModule.ResultsFirstPostDateStruct.ResultsFirstPostDate
+	}},
+	{"status", "OverallStatus", func(s *study) string { return s.ProtocolSection.StatusModule.OverallStatus }},
+	{"brief_title", "BriefTitle", func(s *study) string { return s.ProtocolSection.IdentificationModule.BriefTitle }},
+	{"official_title", "OfficialTitle", func(s *study) string { return s.ProtocolSection.IdentificationModule.OfficialTitle }},
+	{"brief_summary", "BriefSummary", func(s *study) string { return s.ProtocolSection.DescriptionModule.BriefSummary }},
+	{"detailed_description", "DetailedDescription", func(s *study) string { return s.ProtocolSection.DescriptionModule.DetailedDescription }},
+	{"study_type", "StudyType", func(s *study) string { return s.ProtocolSection.DesignModule.StudyType }},
+	{"phase", "Phase", func(s *study) string { return join(s.ProtocolSection.DesignModule.PhaseList.Phase) }},
+	{"allocation", "DesignAllocation", func(s *study) string { return s.ProtocolSection.DesignModule.DesignInfo.DesignAllocation }},
+	{"intervention_model", "DesignInterventionModel", func(s *study) string {
+		return s.ProtocolSection.DesignModule.DesignInfo.DesignInterventionModel
+	}},
+	{"intervention_model_description", "DesignInterventionModelDescription", func(s *study) string {
+		return s.ProtocolSection.DesignModule.DesignInfo.DesignInterventionModelDescription
+	}},
+	{"primary_purpose", "DesignPrimaryPurpose", func(s *study) string { return s.ProtocolSection.DesignModule.DesignInfo.DesignPrimaryPurpose }},
+	{"masking", "DesignMasking", func(s *study) string {
+		return s.ProtocolSection.DesignModule.DesignInfo.DesignMaskingInfo.DesignMasking
+	}},
+	{"", "DesignMaskingDescription", func(s *study) string {
+		return s.ProtocolSection.DesignModule.DesignInfo.DesignMaskingInfo.DesignMaskingDescription
+	}},
+	{"", "DesignWhoMasked", func(s *study) string {
+		return join(s.ProtocolSection.DesignModule.DesignInfo.DesignMaskingInfo.DesignWhoMaskedList.DesignWhoMasked)
+	}},
+	{"condition", "Condition", func(s *study) string { return join(s.ProtocolSection.ConditionsModule.ConditionList.Condition) }},
+	{"", "Keyword", func(s *study) string { return join(s.ProtocolSection.ConditionsModule.KeywordList.Keyword) }},
+	{"intervention_type", "InterventionType", func(s *study) string {
+		list := s.ProtocolSection.ArmsInterventionsModule.InterventionList.Intervention
+		return each(len(list), func(i int) string { return list[i].InterventionType })
+	}},
+	{"intervention_name", "InterventionName", func(s *study) string {
+		list := s.ProtocolSection.ArmsInterventionsModule.InterventionList.Intervention
+		return each(len(list), func(i int) string { return list[i].InterventionName })
+	}},
+	{"intervention_desc", "InterventionDescription", func(s *study) string {
+		list := s.ProtocolSection.ArmsInterventionsModule.InterventionList.Intervention
+		return each(len(list), func(i int) string { return list[i].InterventionDescription })
+	}},
+	{"interventions", "Intervention", func(s *study) string {
+		list := s.ProtocolSection.ArmsInterventionsModule.InterventionList.Intervention
+		return asJSON(list, len(list))
+	}},
+	{"eligibility_criteria", "EligibilityCriteria", func(s *study) string { return s.ProtocolSection.EligibilityModule.EligibilityCriteria }},
+	{"gender", "Gender", func(s *study) string { return s.ProtocolSection.EligibilityModule.Gender }},
+	{"minimum_age", "MinimumAge", func(s *study) string { return s.ProtocolSection.EligibilityModule.MinimumAge }},
+	{"maximum_age", "MaximumAge", func(s *study) string { return s.ProtocolSection.EligibilityModule.MaximumAge }},
+	{"", "StdAge", func(s *study) string { return join(s.ProtocolSection.EligibilityModule.StdAgeList.StdAge) }},
+	{"healthy_volunteers", "HealthyVolunteers", func(s *study) string { return s.ProtocolSection.EligibilityModule.HealthyVolunteers }},
+	{"enrollment", "EnrollmentCount", func(s *study) string { return s.ProtocolSection.DesignModule.EnrollmentInfo.EnrollmentCount }},
+	{"enrollment_type", "EnrollmentType", func(s *study) string { return s.ProtocolSection.DesignModule.EnrollmentInfo.EnrollmentType }},
+	{"primary_outcome_measure", "PrimaryOutcomeMeasure", func(s *study) string {
+		list := s.PrimaryOutcomes()
+		return each(len(list), func(i int) string { return list[i].Measure })
+	}},
+	{"primary_outcome_description", "PrimaryOutcomeDescription", func(s *study) string {
+		list := s.PrimaryOutcomes()
+		return each(len(list), func(i int) string { return list[i].Description })
+	}},
+	{"primary_outcome_time_frame", "PrimaryOutcomeTimeFrame", func(s *study) string {
+		list := s.PrimaryOutcomes()
+		return each(len(list), func(i int) string { return list[i].TimeFrame })
+	}},
+	{"primary_outcomes", "PrimaryOutcome", func(s *study) string {
+		list := s.PrimaryOutcomes()
+		return asJSON(list, len(list))
+	}},
+	{"secondary_outcome_measure", "SecondaryOutcomeMeasure", func(s *study) string {
+		list := s.SecondaryOutcomes()
+		return each(len(list), func(i int) string { return list[i].Measure })
+	}},
+	{"secondary_outcome_description", "SecondaryOutcomeDescription", func(s *study) string {
+		list := s.SecondaryOutcomes()
+		return each(len(list), func(i int) string { return list[i].Description })
+	}},
+	{"secondary_outcome_time_frame", "SecondaryOutcomeTimeFrame", func(s *study) string {
+		list := s.SecondaryOutcomes()
+		return each(len(list), func(i int) string { return list[i].TimeFrame })
+	}},
+	{"secondary_outcomes", "SecondaryOutcome", func(s *study) string {
+		list := s.SecondaryOutcomes()
+		return asJSON(list, len(list))
+	}},
+	{"arm_group_arm_group_label", "ArmGroupLabel", func(s *study) string {
+		list := s.ProtocolSection.ArmsInterventionsModule.ArmGroupList.ArmGroup
+		return each(len(list), func(i int) string { return list[i].ArmGroupLabel })
+	}},
+	{"arm_group_arm_group_type", "ArmGroupType", func(s *study) string {
+		list := s.ProtocolSection.ArmsInterventionsModule.ArmGroupList.ArmGroup
+		return each(len(list), func(i int) string { return list[i].ArmGroupType })
+	}},
+	{"arm_group_description", "ArmGroupDescription", func(s *study) string {
+		list := s.ProtocolSection.ArmsInterventionsModule.ArmGroupList.ArmGroup
+		return each(len(list), func(i int) string { return list[i].ArmGroupDescription })
+	}},
+	{"arm_groups", "ArmGroup", func(s *study) string {
+		list := s.ProtocolSection.ArmsInterventionsModule.ArmGroupList.ArmGroup
+		return asJSON(list, len(list))
+	}},
+	{"location_name", "LocationFacility", func(s *study) string {
+		list := s.Locations()
+		return each(len(list), func(i int) string { return list[i].LocationFacility })
+	}},
+	{"location_city", "LocationCity", func(s *study) string {
+		list := s.Locations()
+		return each(len(list), func(i int) string { return list[i].LocationCity })
+	}},
+	{"location_country", "LocationCountry", func(s *study) string {
+		list := s.Locations()
+		return each(len(list), func(i int) string { return list[i].LocationCountry })
+	}},
+	{"locations", "Location", func(s *study) string {
+		list := s.Locations()
+		return asJSON(list, len(list))
+	}},
+	{"patient_data_sharing_ipd", "IPDSharing", func(s *study) string { return s.ProtocolSection.IPDSharingStatementModule.IPDSharing }},
+	{"sponsors_agency", "LeadSponsorName", func(s *study) string {
+		return s.ProtocolSection.SponsorCollaboratorsModule.LeadSponsor.LeadSponsorName
+	}},
+	{"sponsors_agency_class", "LeadSponsorClass", func(s *study) string {
+		return s.ProtocolSection.SponsorCollaboratorsModule.LeadSponsor.LeadSponsorClass
+	}},
+	{"publications_reference", "ReferenceCitation", func(s *study) string {
+		list := s.ProtocolSection.ReferencesModule.ReferenceList.Reference
+		return each(len(list), func(i int) string { return list[i].ReferenceCitation })
+	}},
+	{"publications_PMID", "ReferencePMID", func(s *study) string {
+		list := s.ProtocolSection.ReferencesModule.ReferenceList.Reference
+		return each(len(list), func(i int) string { return list[i].ReferencePMID })
+	}},
+	{"references", "Reference", func(s *study) string {
+		list := s.ProtocolSection.ReferencesModule.ReferenceList.Reference
+		return asJSON(list, len(list))
+	}},
+	{"results_section", "ResultsSection", func(s *study) string {
+		if s.HasResults() {
+			return "yes"
+		}
+		return ""
+	}},
+	{"", "OverallOfficialName", func(s *study) string {
+		list := s.ProtocolSection.ContactsLocationsModule.OverallOfficialList.OverallOfficial
+		return each(len(list), func(i int) string { return list[i].OverallOfficialName })
+	}},
+	{"", "OverallOfficialAffiliation", func(s *study) string {
+		list := s.ProtocolSection.ContactsLocationsModule.OverallOfficialList.OverallOfficial
+		return each(len(list), func(i int) string { return list[i].OverallOfficialAffiliation })
+	}},
+	{"", "OverallOfficialRole", func(s *study) string {
+		list := s.ProtocolSection.ContactsLocationsModule.OverallOfficialList.OverallOfficial
+		return each(len(list), func(i int) string { return list[i].OverallOfficialRole })
+	}},
+	{"", "CentralContactName", func(s *study) string {
+		list := s.ProtocolSection.ContactsLocationsModule.CentralContactList.CentralContact
+		return each(len(list), func(i int) string { return list[i].CentralContactName })
+	}},
+	{"", "CentralContactRole", func(s *study) string {
+		list := s.ProtocolSection.ContactsLocationsModule.CentralContactList.CentralContact
+		return each(len(list), func(i int) string { return list[i].CentralContactRole })
+	}},
+	{"", "CentralContactPhone", func(s *study) string {
+		list := s.ProtocolSection.ContactsLocationsModule.CentralContactList.CentralContact
+		return each(len(list), func(i int) string { return list[i].CentralContactPhone })
+	}},
+	{"", "CentralContactPhoneExt", func(s *study) string {
+		list := s.ProtocolSection.ContactsLocationsModule.CentralContactList.CentralContact
+		return each(len(list), func(i int) string { return list[i].CentralContactPhoneExt })
+	}},
+	{"", "CentralContactEMail", func(s *study) string {
+		list := s.ProtocolSection.ContactsLocationsModule.CentralContactList.CentralContact
+		return each(len(list), func(i int) string { return list[i].CentralContactEMail })
+	}},
+	{"", "PointOfContactTitle", func(s *study) string { return s.pointOfContact().PointOfContactTitle }},
+	{"", "PointOfContactOrganization", func(s *study) string { return s.pointOfContact().PointOfContactOrganization }},
+	{"", "PointOfContactPhone", func(s *study) string { return s.pointOfContact().PointOfContactPhone }},
+	{"", "PointOfContactPhoneExt", func(s *study) string { return s.pointOfContact().PointOfContactPhoneExt }},
+	{"", "PointOfContactEMail", func(s *study) string { return s.pointOfContact().PointOfContactEMail }},
 }
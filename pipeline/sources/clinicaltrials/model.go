@@ -0,0 +1,366 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// studyRecord is the structure of a single study in the full studies export
+// of clinicaltrials.gov
+type studyRecord struct {
+	Study study
+}
+
+// study is the structure of the study record of clinicaltrials.gov. all values
+// are exported as strings by the api (including counts and dates), lists are
+// kept as lists of objects. see schema.json for the complete definition
+type study struct {
+	ProtocolSection protocolSection
+	ResultsSection  *resultsSection
+}
+
+// protocolSection contains the information registered for the study
+type protocolSection struct {
+	IdentificationModule struct {
+		NCTId          string
+		OrgStudyIdInfo struct {
+			OrgStudyId string
+		}
+		SecondaryIdInfoList struct {
+			SecondaryIdInfo []secondaryID
+		}
+		BriefTitle    string
+		OfficialTitle string
+		Acronym       string
+	}
+	StatusModule struct {
+		OverallStatus   string
+		WhyStopped      string
+		StartDateStruct struct {
+			StartDate     string
+			StartDateType string
+		}
+		PrimaryCompletionDateStruct struct {
+			PrimaryCompletionDate     string
+			PrimaryCompletionDateType string
+		}
+		CompletionDateStruct struct {
+			CompletionDate     string
+			CompletionDateType string
+		}
+		StudyFirstSubmitDate     string
+		StudyFirstPostDateStruct struct {
+			StudyFirstPostDate     string
+			StudyFirstPostDateType string
+		}
+		ResultsFirstPostDateStruct struct {
+			ResultsFirstPostDate     string
+			ResultsFirstPostDateType string
+		}
+		LastUpdatePostDateStruct struct {
+			LastUpdatePostDate     string
+			LastUpdatePostDateType string
+		}
+	}
+	SponsorCollaboratorsModule struct {
+		LeadSponsor struct {
+			LeadSponsorName  string
+			LeadSponsorClass string
+		}
+		CollaboratorList struct {
+			Collaborator []struct {
+				CollaboratorName  string
+				CollaboratorClass string
+			}
+		}
+	}
+	DescriptionModule struct {
+		BriefSummary        string
+		DetailedDescription string
+	}
+	ConditionsModule struct {
+		ConditionList struct {
+			Condition []string
+		}
+		KeywordList struct {
+			Keyword []string
+		}
+	}
+	DesignModule struct {
+		StudyType string
+		PhaseList struct {
+			Phase []string
+		}
+		DesignInfo struct {
+			DesignAllocation                   string
+			DesignInterventionModel            string
+			DesignInterventionModelDescription string
+			DesignPrimaryPurpose               string
+			DesignMaskingInfo                  struct {
+				DesignMasking            string
+				DesignMaskingDescription string
+				DesignWhoMaskedList      struct {
+					DesignWhoMasked []string
+				}
+			}
+		}
+		EnrollmentInfo struct {
+			EnrollmentCount string
+			EnrollmentType  string
+		}
+	}
+	ArmsInterventionsModule struct {
+		ArmGroupList struct {
+			ArmGroup []armGroup
+		}
+		InterventionList struct {
+			Intervention []intervention
+		}
+	}
+	OutcomesModule struct {
+		PrimaryOutcomeList struct {
+			PrimaryOutcome []struct {
+				PrimaryOutcomeMeasure     string
+				PrimaryOutcomeDescription string
+				PrimaryOutcomeTimeFrame   string
+			}
+		}
+		SecondaryOutcomeList struct {
+			SecondaryOutcome []struct {
+				SecondaryOutcomeMeasure     string
+				SecondaryOutcomeDescription string
+				SecondaryOutcomeTimeFrame   string
+			}
+		}
+		OtherOutcomeList struct {
+			OtherOutcome []struct {
+				OtherOutcomeMeasure     string
+				OtherOutcomeDescription string
+				OtherOutcomeTimeFrame   string
+			}
+		}
+	}
+	EligibilityModule struct {
+		EligibilityCriteria string
+		HealthyVolunteers   string
+		Gender              string
+		MinimumAge          string
+		MaximumAge          string
+		StdAgeList          struct {
+			StdAge []string
+		}
+	}
+	ContactsLocationsModule struct {
+		CentralContactList struct {
+			CentralContact []contact
+		}
+		OverallOfficialList struct {
+			OverallOfficial []official
+		}
+		LocationList struct {
+			Location []location
+		}
+	}
+	ReferencesModule struct {
+		ReferenceList struct {
+			Reference []reference
+		}
+	}
+	IPDSharingStatementModule struct {
+		IPDSharing            string
+		IPDSharingDescription string
+	}
+}
+
+// resultsSection contains the results posted for the study. the individual
+// measurements are not decoded, since they are not used in covebasic
+type resultsSection struct {
+	ParticipantFlowModule struct {
+		FlowGroupList struct {
+			FlowGroup []resultGroup
+		}
+	}
+	OutcomeMeasuresModule struct {
+		OutcomeMeasureList struct {
+			OutcomeMeasure []outcomeMeasure
+		}
+	}
+	AdverseEventsModule struct {
+		EventsTimeFrame string
+		EventGroupList  struct {
+			EventGroup []eventGroup
+		}
+	}
+	MoreInfoModule struct {
+		PointOfContact pointOfContact
+	}
+}
+
+// secondaryID is the structure of the secondary ids of a study
+type secondaryID struct {
+	SecondaryId     string
+	SecondaryIdType string
+}
+
+// armGroup is the structure of the arm groups of clinicaltrials.gov
+type armGroup struct {
+	ArmGroupLabel            string
+	ArmGroupType             string
+	ArmGroupDescription      string
+	ArmGroupInterventionList struct {
+		ArmGroupInterventionName []string
+	}
+}
+
+// intervention is the structure of the interventions of clinicaltrials.gov
+type intervention struct {
+	InterventionType        string
+	InterventionName        string
+	InterventionDescription string
+}
+
+// outcome contains the information of a primary or secondary outcome. the
+// outcomes of clinicaltrials.gov use different field names for each list
+type outcome struct {
+	Measure     string `json:"measure"`
+	Description string `json:"description,omitempty"`
+	TimeFrame   string `json:"time_frame,omitempty"`
+}
+
+// location is the structure of the locations of clinicaltrials.gov
+type location struct {
+	LocationFacility string
+	LocationStatus   string `json:",omitempty"`
+	LocationCity     string
+	LocationState    string `json:",omitempty"`
+	LocationZip      string `json:",omitempty"`
+	LocationCountry  string
+}
+
+// contact is the structure of the central contacts of clinicaltrials.gov
+type contact struct {
+	CentralContactName     string
+	CentralContactRole     string
+	CentralContactPhone    string
+	CentralContactPhoneExt string
+	CentralContactEMail    string
+}
+
+// official is the structure of the overall officials of clinicaltrials.gov
+type official struct {
+	OverallOfficialName        string
+	OverallOfficialAffiliation string
+	OverallOfficialRole        string
+}
+
+// reference is the structure of the references of clinicaltrials.gov
+type reference struct {
+	ReferencePMID     string
+	ReferenceType     string
+	ReferenceCitation string
+}
+
+// pointOfContact is the structure of the contact for the results
+type pointOfContact struct {
+	PointOfContactTitle        string
+	PointOfContactOrganization string
+	PointOfContactEMail        string
+	PointOfContactPhone        string
+	PointOfContactPhoneExt     string
+}
+
+// resultGroup is the structure of the groups of the participant flow
+type resultGroup struct {
+	FlowGroupId          string
+	FlowGroupTitle       string
+	FlowGroupDescription string
+}
+
+// outcomeMeasure is the structure of the outcome measures of the results
+type outcomeMeasure struct {
+	OutcomeMeasureType                  string
+	OutcomeMeasureTitle                 string
+	OutcomeMeasureDescription           string
+	OutcomeMeasurePopulationDescription string
+	OutcomeMeasureReportingStatus       string
+	OutcomeMeasureParamType             string
+	OutcomeMeasureUnitOfMeasure         string
+	OutcomeMeasureTimeFrame             string
+}
+
+// eventGroup is the structure of the adverse event groups of the results
+type eventGroup struct {
+	EventGroupId                 string
+	EventGroupTitle              string
+	EventGroupDeathsNumAffected  string
+	EventGroupDeathsNumAtRisk    string
+	EventGroupSeriousNumAffected string
+	EventGroupSeriousNumAtRisk   string
+}
+
+// PrimaryOutcomes will return the registered primary outcomes of the study
+func (s *study) PrimaryOutcomes() []outcome {
+	list := s.ProtocolSection.OutcomesModule.PrimaryOutcomeList.PrimaryOutcome
+	outcomes := make([]outcome, len(list))
+	for i, o := range list {
+		outcomes[i] = outcome{o.PrimaryOutcomeMeasure, o.PrimaryOutcomeDescription, o.PrimaryOutcomeTimeFrame}
+	}
+	return outcomes
+}
+
+// SecondaryOutcomes will return the registered secondary outcomes of the study
+func (s *study) SecondaryOutcomes() []outcome {
+	list := s.ProtocolSection.OutcomesModule.SecondaryOutcomeList.SecondaryOutcome
+	outcomes := make([]outcome, len(list))
+	for i, o := range list {
+		outcomes[i] = outcome{o.SecondaryOutcomeMeasure, o.SecondaryOutcomeDescription, o.SecondaryOutcomeTimeFrame}
+	}
+	return outcomes
+}
+
+// Locations will return the locations of the study
+func (s *study) Locations() []location {
+	return s.ProtocolSection.ContactsLocationsModule.LocationList.Location
+}
+
+// HasResults will check if results are posted for the study
+func (s *study) HasResults() bool {
+	return s.ResultsSection != nil
+}
+
+// pointOfContact will return the contact for the results of the study or an
+// empty contact if no results are posted
+func (s *study) pointOfContact() pointOfContact {
+	if s.ResultsSection == nil {
+		return pointOfContact{}
+	}
+	return s.ResultsSection.MoreInfoModule.PointOfContact
+}
+
+// join will concatenate the given values with semicolon
+func join(values []string) string {
+	return strings.Join(values, "; ")
+}
+
+// each will apply the given function to all items of a list with the given
+// length and concatenate the results with semicolon
+func each(count int, value func(i int) string) string {
+	values := make([]string, count)
+	for i := range values {
+		values[i] = value(i)
+	}
+	return join(values)
+}
+
+// asJSON will encode the given list as json to keep its structure in the csv
+// export. empty lists are returned as empty string
+func asJSON(list interface{}, count int) string {
+	if count == 0 {
+		return ""
+	}
+	content, err := json.Marshal(list)
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
@@ -49,6 +49,9 @@ func main() {
 	ioutil.WriteFile("output.json", payload, 0777)
 
 	// import the new basic records into ninox
-	ninox.UpdateRecords(ninox.CoveBasicURL, changes)
+	err = ninox.UpdateRecords(ninox.CoveBasicURL, changes)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
 
 }
@@ -4,11 +4,17 @@ import (
 	"fmt"
 	"strings"
 
+	"dkfbasel.ch/covid-evidence/ages"
+	"dkfbasel.ch/covid-evidence/arms"
+	"dkfbasel.ch/covid-evidence/countries"
+	"dkfbasel.ch/covid-evidence/helpers"
 	"dkfbasel.ch/covid-evidence/ninox"
+	"dkfbasel.ch/covid-evidence/registries"
+	"dkfbasel.ch/covid-evidence/review"
 )
 
 // convertRecords will convert the ictrp records to covebasic
-func convertRecords(screeningRecords []ninox.Record, basicIndex ninox.Index) []*ninox.Record {
+func convertRecords(screeningRecords []ninox.Record, basicIndex *ninox.Index) []*ninox.Record {
 
 	const sourceName = "ICTRP"
 
@@ -22,7 +28,7 @@ func convertRecords(screeningRecords []ninox.Record, basicIndex ninox.Index) []*
 		// skip all records that exist in ninox already
 		_, ok := basicIndex.Get(sourceID)
 		if ok {
-			fmt.Printf("record exists already: %s, %s\n", sourceID)
+			fmt.Printf("record exists already: %s\n", sourceID)
 			continue
 		}
 
@@ -31,10 +37,13 @@ func convertRecords(screeningRecords []ninox.Record, basicIndex ninox.Index) []*
 		r.Fields = make(map[string]interface{})
 		r.Fields["source"] = sourceName
 		r.Fields["source_id"] = sourceID
-		r.Fields["review_status"] = "prefilled automatically"
+		r.Fields["review_status"] = string(review.Prefilled)
 
 		r.Update("entry_type", "registration", nil)
 
+		// registry_ids, all identifiers of the trial in any registry
+		r.Update("registry_ids", registries.List(sourceID, s.Field("Secondary IDs")), helpers.AsGenerated)
+
 		r.Update("url", s.Field("web address"), nil)
 
 		r.Update("title", s.Field("Scientific title"), nil)
@@ -45,46 +54,58 @@ func convertRecords(screeningRecords []ninox.Record, basicIndex ninox.Index) []*
 		r.Update("status", s.Field("Recruitment Status"), toLowerCase)
 		r.Update("status_date", s.Field("Last Refreshed On"), toIsoDate)
 
+		// country contains the country name or international if there are
+		// multiple countries, the countries field contains all iso codes and
+		// countries_unmatched the countries without iso code for review
 		r.Update("country", s.Field("Countries"), func(country string) (interface{}, bool) {
-			// country field may contain multiple countries separated by semicolon
-			// -> use international if there are multiple countries
-			// -> use the country name if it is the same multiple times
-			if strings.Contains(country, ";") == false {
-				return country, false
-			}
-
-			items := strings.Split(country, "; ")
-			first := items[0]
-			international := false
-			for _, c := range items {
-				if c != first {
-					international = true
-				}
-			}
-			if international {
-				return "international", true
-			}
-
-			return first, true
+			return countries.Summary(country), true
+		})
+		r.Update("countries", s.Field("Countries"), func(country string) (interface{}, bool) {
+			return countries.List(country), true
+		})
+		r.Update("countries_unmatched", s.Field("Countries"), func(country string) (interface{}, bool) {
+			return countries.Unmatched(country), true
 		})
 
 		r.Update("randomized", s.Field("Study design"), toLowerCase)
 
 		r.Update("population_condition", s.Field("condition"), nil)
 
+		// population_age, derived from the minimum and maximum age, and the
+		// minimum and maximum age in years
+		r.Update("population_age", ages.Categories(s.Field("Inclusion agemin"), s.Field("Inclusion agemax")),
+			helpers.AsGenerated)
+		r.Update("population_age_min", s.Field("Inclusion agemin"), ages.Years)
+		r.Update("population_age_max", s.Field("Inclusion agemax"), ages.Years)
+
 		r.Update("intervention_name", s.Field("Intervention"), nil)
 
+		// n_arms and control_type, derived from the groups in the intervention
+		armList := arms.FromIctrp(s.Field("Intervention"))
+		r.Update("n_arms", len(armList), func(m string) (interface{}, bool) {
+			if m == "0" {
+				return "", true
+			}
+			count, _ := toInt(m)
+			return count, true
+		})
+		r.Update("control_type", arms.ControlType(armList), helpers.AsGenerated)
+
 		r.Update("out_primary_measure", s.Field("Primary outcome"), nil)
 
 		r.Update("start_date", s.Field("Date enrollement"), toIsoDate)
 
-		// results_available if a results url is given
-		r.Update("results_available", s.Field("results url link"), func(url string) (interface{}, bool) {
-			if url == "" {
-				return "no", true
-			}
-			return "yes", true
-		})
+		// results_available if results are reported or a results url is given
+		resultsAvailable := "no"
+		if strings.EqualFold(s.Field("results yes no"), "yes") || s.Field("results url link") != "" {
+			resultsAvailable = "yes"
+		}
+		r.Update("results_available", resultsAvailable, helpers.AsGenerated)
+
+		r.Update("results_date", s.Field("results date posted"), toIsoDate)
+
+		// results are expected within 12 months of the completion date
+		r.Update("results_expected_date", s.Field("results date completed"), helpers.ToResultsExpectedDate)
 
 		r.Update("inclusion_criteria", s.Field("Inclusion Criteria"), nil)
 		r.Update("exclusion_criteria", s.Field("Exclusion Criteria"), nil)
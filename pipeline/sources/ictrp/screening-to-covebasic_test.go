@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"dkfbasel.ch/covid-evidence/ninox"
+)
+
+func TestConvertResultsExpectedDate(t *testing.T) {
+
+	tests := []struct {
+		completion string
+		want       string
+	}{
+		{"May 30, 2020", "2021-05-30"},
+		{"2020-05-30", "2021-05-30"},
+		{"January 2020", "2021-01"},
+		{"30.05.20", "2021-05-30"},
+		{"unknown", ""},
+	}
+
+	for _, test := range tests {
+
+		screening := []ninox.Record{{ID: 1, Fields: map[string]interface{}{
+			"TrialID":                "ChiCTR2000029308",
+			"results date completed": test.completion,
+		}}}
+
+		records := convertRecords(screening, ninox.NewIndex())
+		if len(records) != 1 {
+			t.Fatalf("convertRecords returned %d records, expected 1", len(records))
+		}
+
+		if got := records[0].Field("results_expected_date"); got != test.want {
+			t.Errorf("results_expected_date of %q = %q, expected %q", test.completion, got, test.want)
+		}
+	}
+}
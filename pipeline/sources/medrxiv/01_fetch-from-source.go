@@ -7,10 +7,14 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"dkfbasel.ch/covid-evidence/manifest"
+	"dkfbasel.ch/covid-evidence/queries"
 )
 
 type exportData struct {
@@ -37,7 +41,15 @@ func Convert() {
 
 	timestamp := time.Now().Format("20060102-150405")
 
-	response, err := http.Get("https://connect.medrxiv.org/relate/collection_json.php?grp=181")
+	// the collection is defined in the search queries
+	query, err := queries.Latest("medRxiv", "covid")
+	if err != nil {
+		log.Printf("could not find query: %+v", err)
+		return
+	}
+
+	response, err := http.Get(fmt.Sprintf("https://connect.medrxiv.org/relate/collection_json.php?grp=%s",
+		url.QueryEscape(query.Expression)))
 	if err != nil {
 		log.Println("could not fetch data")
 		return
@@ -50,7 +62,7 @@ func Convert() {
 		return
 	}
 
-	ioutil.WriteFile(fmt.Sprintf("./exports/data_%s.json", timestamp), content, 0644)
+	err = ioutil.WriteFile(fmt.Sprintf("./exports/data_%s.json", timestamp), content, 0644)
 	if err != nil {
 		log.Println("coud not open file content")
 		return
@@ -106,5 +118,27 @@ func Convert() {
 	}
 
 	writer.Flush()
+	out.Close() // nolint:errcheck
+
+	// write the manifest with the query used and the checksums of the export
+	export := fmt.Sprintf("./exports/data_%s", timestamp)
+
+	m := manifest.New("fetch", query.Source)
+	m.Query = query.ID()
+	m.Expression = query.Expression
+	m.Counts["preprints"] = len(dta.Rels)
+
+	for _, output := range []string{export + ".json", export + ".csv"} {
+		err = m.AddOutput(output)
+		if err != nil {
+			log.Printf("could not add export to manifest: %+v", err)
+			return
+		}
+	}
+
+	err = m.Write(manifest.Path(export, "fetch"))
+	if err != nil {
+		log.Printf("could not write manifest: %+v", err)
+	}
 
 }
@@ -5,11 +5,13 @@ import (
 
 	"dkfbasel.ch/covid-evidence/helpers"
 	"dkfbasel.ch/covid-evidence/ninox"
+	"dkfbasel.ch/covid-evidence/registries"
+	"dkfbasel.ch/covid-evidence/review"
 )
 
 // convertRecords will convert the ictrp records to covebasic
 func convertRecords(screeningRecords []ninox.Record, basicRecords []ninox.Record,
-	basicIndex ninox.Index) []*ninox.Record {
+	basicIndex *ninox.Index) []*ninox.Record {
 
 	sourceName := "medRxiv"
 
@@ -21,7 +23,7 @@ func convertRecords(screeningRecords []ninox.Record, basicRecords []ninox.Record
 		sourceID := s.Field("ID")
 
 		// skip all records that exist in ninox already
-		info, ok := basicIndex[sourceID]
+		info, ok := basicIndex.Get(sourceID)
 		if ok {
 			fmt.Printf("record exists already: %s, %s\n", sourceID, info.Table)
 			continue
@@ -34,7 +36,7 @@ func convertRecords(screeningRecords []ninox.Record, basicRecords []ninox.Record
 		r.Fields["source"] = sourceName
 		r.Fields["source_id"] = sourceID
 
-		r.Fields["review_status"] = "prefilled automatically"
+		r.Fields["review_status"] = string(review.Prefilled)
 
 		r.Update("entry_type", "preprint", nil)
 
@@ -46,6 +48,9 @@ func convertRecords(screeningRecords []ninox.Record, basicRecords []ninox.Record
 
 		r.Update("doi", s.Field("rel_doi"), nil)
 
+		// registry_ids, trial registrations mentioned in the preprint
+		r.Update("registry_ids", registries.List(s.Field("rel_title"), s.Field("rel_abs")), helpers.AsGenerated)
+
 		r.Update("status_date", s.Field("rel_date"), helpers.ToIsoDate)
 
 		// nothing to do, if the record was not changed
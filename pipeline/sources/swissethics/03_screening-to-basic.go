@@ -5,11 +5,13 @@ import (
 
 	"dkfbasel.ch/covid-evidence/helpers"
 	"dkfbasel.ch/covid-evidence/ninox"
+	"dkfbasel.ch/covid-evidence/registries"
+	"dkfbasel.ch/covid-evidence/review"
 )
 
 // convertRecords will convert the ictrp records to covebasic
 func convertRecords(screeningRecords []ninox.Record, basicRecords []ninox.Record,
-	basicIndex ninox.Index) []*ninox.Record {
+	basicIndex *ninox.Index) []*ninox.Record {
 
 	// initialize the updates/inserts
 	updates := []*ninox.Record{}
@@ -19,11 +21,11 @@ func convertRecords(screeningRecords []ninox.Record, basicRecords []ninox.Record
 		sourceID := s.Field("Project ID")
 
 		// skip all records that exist in ninox already
-		info, ok := basicIndex[sourceID]
+		info, ok := basicIndex.Get(sourceID)
 		if ok {
 			fmt.Printf("record exists already: %s, %s\n", sourceID, info.Table)
 
-			if info.Record.Field("review_status") != "prefilled automatically" {
+			if !review.Automated(info.Record.Field("review_status")) {
 				continue
 			}
 
@@ -40,15 +42,19 @@ func convertRecords(screeningRecords []ninox.Record, basicRecords []ninox.Record
 		r.Fields["source"] = "Ethics committees (CH)"
 		r.Fields["source_id"] = sourceID
 
-		r.Fields["review_status"] = "prefilled automatically"
+		r.Fields["review_status"] = string(review.Prefilled)
 		r.Fields["is_trial"] = "yes"
 
 		r.Update("entry_type", "ethics", nil)
 
+		// registry_ids, the project id is the basec number of the study
+		r.Update("registry_ids", registries.List("BASEC "+sourceID), helpers.AsGenerated)
+
 		r.Update("title", s.Field("Project Title"), nil)
 		r.Update("authors", s.Field("Principal Investigator"), nil)
 
 		r.Update("country", "Switzerland", nil)
+		r.Update("countries", "CH", nil)
 
 		r.Update("status_date", s.Field("Date final decision"), helpers.ToIsoDate)
 